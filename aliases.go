@@ -0,0 +1,70 @@
+package cfgenv
+
+import (
+	"reflect"
+)
+
+// DeprecatedNameHandler is an option that can be passed to Load or LoadAs
+// and is notified whenever a deprecated env var name (see env tag 'alias') is set - e.g. to log or alert on configs
+// that need migrating
+type DeprecatedNameHandler interface {
+	// DeprecatedName is called with the deprecated name that is set, the current name and the path of the config struct
+	// field, e.g. "Database.Host"
+	DeprecatedName(deprecated string, current string, field string)
+}
+
+type deprecatedNameHandler struct {
+	fn func(deprecated string, current string, field string)
+}
+
+func (d *deprecatedNameHandler) DeprecatedName(deprecated string, current string, field string) {
+	d.fn(deprecated, current, field)
+}
+
+// NewDeprecatedNameHandler creates a new DeprecatedNameHandler that calls the provided function
+func NewDeprecatedNameHandler(fn func(deprecated string, current string, field string)) DeprecatedNameHandler {
+	return &deprecatedNameHandler{fn: fn}
+}
+
+// StrictAliasesOption is an option that can be passed to Load or LoadAs
+// and determines whether it is an error for both the current and a deprecated env var name (see env tag 'alias')
+// to be set with different values
+//
+// By default, the current name takes precedence and the deprecated names are ignored
+type StrictAliasesOption interface {
+	// StrictAliases returns whether it is an error for the current and a deprecated name to be set with different values
+	StrictAliases() bool
+}
+
+type strictAliasesOpt struct{}
+
+func (s *strictAliasesOpt) StrictAliases() bool {
+	return true
+}
+
+// NewStrictAliases creates a new StrictAliasesOption - where it is an error for both the current and a deprecated env var
+// name to be set with different values
+func NewStrictAliases() StrictAliasesOption {
+	return &strictAliasesOpt{}
+}
+
+// lookupAliased looks up the env var for a field - falling back through the field's aliases (deprecated names) in order
+func (o *opts) lookupAliased(name string, prefix string, path string, fld reflect.StructField, fi *fieldInfo) (string, bool, error) {
+	raw, ok := o.reader.LookupEnv(name)
+	for _, alias := range fi.aliases {
+		aliasName := o.naming.BuildName(prefix, o.separator.GetSeparator(), fld, alias)
+		aliasRaw, aliasOk := o.reader.LookupEnv(aliasName)
+		if !aliasOk {
+			continue
+		}
+		if o.deprecatedNameHandler != nil {
+			o.deprecatedNameHandler.DeprecatedName(aliasName, name, path)
+		}
+		if !ok {
+			raw, ok = aliasRaw, true
+		} else if o.strictAliases && aliasRaw != raw {
+			return raw, ok, &AliasConflictError{Name: name, Alias: aliasName, Field: path, Type: fld.Type}
+		}
+	}
+	return raw, ok, nil
+}
@@ -0,0 +1,114 @@
+package cfgenv
+
+import (
+	"errors"
+	"github.com/go-andiamo/gopt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type testAliasSub struct {
+	Host string `env:"alias=HOSTNAME"`
+}
+
+type testAliasConfig struct {
+	Host     string             `env:"name=DB_HOST,alias=DB_HOSTNAME|DATABASE_HOST"`
+	Port     int                `env:"alias='DB_PORT_NUMBER'"`
+	Timeout  gopt.Optional[int] `env:"alias=TIMEOUT_SECS"`
+	Routes   []string           `env:"json,optional,alias=ROUTE_LIST"`
+	Sub      testAliasSub       `env:"prefix=SUB"`
+	Optional *string            `env:"alias=OLD_OPTIONAL"`
+	Tags     map[string]string  `env:"alias=LABELS"`
+}
+
+func TestLoad_Aliases(t *testing.T) {
+	type deprecation struct {
+		deprecated, current, field string
+	}
+	deprecations := make([]deprecation, 0)
+	handler := NewDeprecatedNameHandler(func(deprecated string, current string, field string) {
+		deprecations = append(deprecations, deprecation{deprecated, current, field})
+	})
+	cfg := &testAliasConfig{}
+	err := Load(cfg, handler, MapEnvReader{
+		"DATABASE_HOST":  "db2",
+		"DB_HOSTNAME":    "db1",
+		"DB_PORT_NUMBER": "5432",
+		"TIMEOUT_SECS":   "30",
+		"ROUTE_LIST":     `["a"]`,
+		"SUB_HOSTNAME":   "sub",
+		"TAGS":           "a:1",
+		"LABELS":         "b:2",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "db1", cfg.Host)
+	assert.Equal(t, 5432, cfg.Port)
+	assert.Equal(t, 30, cfg.Timeout.Default(0))
+	assert.Equal(t, []string{"a"}, cfg.Routes)
+	assert.Equal(t, "sub", cfg.Sub.Host)
+	assert.Nil(t, cfg.Optional)
+	assert.Equal(t, map[string]string{"a": "1"}, cfg.Tags)
+	assert.Equal(t, []deprecation{
+		{"DB_HOSTNAME", "DB_HOST", "Host"},
+		{"DATABASE_HOST", "DB_HOST", "Host"},
+		{"DB_PORT_NUMBER", "PORT", "Port"},
+		{"TIMEOUT_SECS", "TIMEOUT", "Timeout"},
+		{"ROUTE_LIST", "ROUTES", "Routes"},
+		{"SUB_HOSTNAME", "SUB_HOST", "Sub.Host"},
+		{"LABELS", "TAGS", "Tags"},
+	}, deprecations)
+
+	err = Load(cfg, handler, handler)
+	require.Error(t, err)
+	assert.Equal(t, "multiple deprecated name handlers", err.Error())
+}
+
+func TestLoad_Aliases_Strict(t *testing.T) {
+	env := MapEnvReader{
+		"DB_HOST":       "db",
+		"DB_HOSTNAME":   "db",
+		"DATABASE_HOST": "other",
+		"PORT":          "1",
+		"SUB_HOST":      "sub",
+		"TAGS":          "a:1",
+	}
+	cfg := &testAliasConfig{}
+	err := Load(cfg, env)
+	require.NoError(t, err)
+	assert.Equal(t, "db", cfg.Host)
+
+	err = Load(cfg, NewStrictAliases(), env)
+	require.Error(t, err)
+	assert.Equal(t, "env var 'DB_HOST' and deprecated env var 'DATABASE_HOST' are both set with different values", err.Error())
+	var ace *AliasConflictError
+	require.True(t, errors.As(err, &ace))
+	assert.Equal(t, "Host", ace.Field)
+	assert.Equal(t, "DATABASE_HOST", ace.Alias)
+
+	delete(env, "DATABASE_HOST")
+	err = Load(cfg, NewStrictAliases(), env)
+	require.NoError(t, err)
+
+	err = Load(cfg, NewStrictAliases(), NewStrictAliases())
+	require.Error(t, err)
+	assert.Equal(t, "multiple strict aliases options", err.Error())
+}
+
+func TestLoad_Aliases_Errors(t *testing.T) {
+	err := Load(&testAliasConfig{}, MapEnvReader{"DB_HOSTNAME": "db", "DB_PORT_NUMBER": "x", "SUB_HOST": "x", "TAGS": "a:1"})
+	require.Error(t, err)
+	assert.Equal(t, "env var 'PORT' is not an int", err.Error())
+
+	err = Load(&struct {
+		Sub testAliasSub `env:"alias=OLD"`
+	}{}, MapEnvReader{})
+	require.Error(t, err)
+	assert.Equal(t, "cannot use env tags 'alias', 'file' or 'unset' on field 'Sub' (only for fields loaded from a single env var)", err.Error())
+
+	err = Load(&struct {
+		Test string `env:"alias"`
+	}{}, MapEnvReader{})
+	require.Error(t, err)
+	assert.Equal(t, "cannot use env tag 'alias' without value on field 'Test' (use quotes if necessary)", err.Error())
+}
@@ -0,0 +1,139 @@
+package cfgenv
+
+import (
+	"errors"
+	"github.com/go-andiamo/splitter"
+	"reflect"
+	"strings"
+)
+
+// delimitedLevels returns the number of collection levels that are split by delimiters (prefix and match maps are not split,
+// but their values may be)
+func (fi *fieldInfo) delimitedLevels() int {
+	if fi.isPrefixedMap || fi.isMatchedMap {
+		return fi.depth - 1
+	}
+	return fi.depth
+}
+
+// delimiterAt returns the delimiter for the collection nesting level (using the `delims` tag if specified)
+func (fi *fieldInfo) delimiterAt(level int) string {
+	if len(fi.delimiters) > 0 {
+		if level < len(fi.delimiters) {
+			return fi.delimiters[level]
+		}
+		return fi.delimiters[len(fi.delimiters)-1]
+	}
+	return fi.delimiter
+}
+
+// nestedCollectionDepth returns the collection nesting depth of a collection item type (0 if the item type is not itself
+// a slice or map) and whether the item type (and any nested item types) are supported
+func nestedCollectionDepth(it reflect.Type) (int, bool) {
+	switch {
+	case isTextType(it):
+		return 0, true
+	case it.Kind() == reflect.Slice && it.Elem().Kind() != reflect.Uint8:
+		depth, ok := nestedCollectionDepth(it.Elem())
+		return depth + 1, ok
+	case it.Kind() == reflect.Map:
+		if !isSupportedItemType(it.Key()) {
+			return 0, false
+		} else if isSetItemType(it.Elem()) {
+			return 1, true
+		}
+		depth, ok := nestedCollectionDepth(it.Elem())
+		return depth + 1, ok
+	case it.Kind() == reflect.Slice:
+		// []byte
+		return 0, true
+	}
+	return 0, isSupportedItemType(it)
+}
+
+// isCollectionType determines whether the type is a slice (other than []byte) or map
+func isCollectionType(t reflect.Type) bool {
+	return !isTextType(t) && ((t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8) || t.Kind() == reflect.Map)
+}
+
+// isSetItemType determines whether a map item type denotes a set (i.e. struct{})
+func isSetItemType(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.NumField() == 0
+}
+
+// isSupportedItemType determines whether a collection item type is a native type or text type (or pointer to either)
+func isSupportedItemType(it reflect.Type) bool {
+	if it.Kind() == reflect.Pointer {
+		it = it.Elem()
+	}
+	return isNativeType(it.Kind()) || isTextType(it)
+}
+
+// splitItems splits a collection value by the delimiter (into at most n items - or all items if n < 0)
+//
+// if the field is quoted, delimiters within double quotes are not split on - the quotes and escapes are retained in the
+// items (so that nested collection items can be split further) and are removed by unquoteItem (quote-aware splitting
+// only applies to single character delimiters)
+func (fi *fieldInfo) splitItems(raw string, delim string, n int) ([]string, error) {
+	if rs := []rune(delim); fi.quoted && len(rs) == 1 {
+		parts, err := splitter.MustCreateSplitter(rs[0], splitter.DoubleQuotesBackSlashEscaped).Split(raw)
+		if err != nil {
+			return nil, errors.New("unterminated quote")
+		} else if n > 0 && len(parts) > n {
+			parts = append(parts[:n-1], strings.Join(parts[n-1:], delim))
+		}
+		return parts, nil
+	}
+	return strings.SplitN(raw, delim, n), nil
+}
+
+// itemUnquoter strips the quotes (and escapes) from an item split by splitItems - the separator is NUL, which cannot
+// occur in env vars, so that items are never split further
+var itemUnquoter = splitter.MustCreateSplitter(0, splitter.DoubleQuotesBackSlashEscaped).
+	AddDefaultOptions(&unquoteOption{})
+
+// itemEscaper escapes backslashes and double quotes within quoted items (and itemUnescaper reverses it)
+var itemEscaper, itemUnescaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`), strings.NewReplacer(`\\`, `\`, `\"`, `"`)
+
+// unquoteOption is a splitter.Option that strips the quotes from, and unescapes, the quoted parts of an item
+type unquoteOption struct{}
+
+func (o *unquoteOption) Apply(s string, pos int, totalLen int, captured int, skipped int, isLast bool, subParts ...splitter.SubPart) (string, bool, error) {
+	var sb strings.Builder
+	for _, sub := range subParts {
+		if str := sub.String(); sub.IsQuote() {
+			sb.WriteString(itemUnescaper.Replace(str[1 : len(str)-1]))
+		} else {
+			sb.WriteString(str)
+		}
+	}
+	return sb.String(), true, nil
+}
+
+// unquoteItem removes quotes and escapes from a (non-collection) item split by splitItems
+func (fi *fieldInfo) unquoteItem(s string) string {
+	if !fi.quoted || !strings.Contains(s, `"`) {
+		return s
+	} else if parts, err := itemUnquoter.Split(s); err == nil && len(parts) == 1 {
+		return parts[0]
+	}
+	return s
+}
+
+// quoteItem quotes a (non-collection) item if the field is quoted and the item contains any delimiter, the separator,
+// quotes or backslashes - so that the item is split intact by splitItems
+func (fi *fieldInfo) quoteItem(s string) string {
+	if fi.quoted && (strings.ContainsAny(s, `"\`) || strings.Contains(s, fi.separator) || strings.Contains(s, fi.delimiter) ||
+		strings.ContainsAny(s, strings.Join(fi.delimiters, ""))) {
+		return `"` + itemEscaper.Replace(s) + `"`
+	}
+	return s
+}
+
+// itemRaw returns the raw string for a collection item - unquoted, unless the item is itself a collection (which is split further)
+func (fi *fieldInfo) itemRaw(raw string, iv reflect.Value) string {
+	if isCollectionType(iv.Type()) {
+		return raw
+	}
+	return fi.unquoteItem(raw)
+}
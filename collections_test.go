@@ -0,0 +1,246 @@
+package cfgenv
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestLoad_NestedCollections(t *testing.T) {
+	type config struct {
+		Routes   map[string][]string        `env:"delims=';,'"`
+		Ports    map[string][]int           `env:"delims=';,'"`
+		Matrix   [][]int                    `env:"delims=';,'"`
+		Deep     [][][]string               `env:"delims='|;,'"`
+		Weights  []map[string]float64       `env:"delims=';,'"`
+		Nested   map[string]map[string]int  `env:"delims=';,',sep='='"`
+		PtrItems [][]*int                   `env:"delims=';,'"`
+		Sets     map[string]map[string]bool `env:"delims=';,',sep='='"`
+	}
+	cfg := &config{}
+	err := Load(cfg, MapEnvReader{
+		"ROUTES":    "a:x,y;b:z",
+		"PORTS":     "a:1,2;b:3",
+		"MATRIX":    "1,2;3,4,5",
+		"DEEP":      "a,b;c|d",
+		"WEIGHTS":   "a:1.5,b:2;c:3",
+		"NESTED":    "a=x=1,y=2;b=z=3",
+		"PTR_ITEMS": "1,2;3",
+		"SETS":      "a=x,y;b=z",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"a": {"x", "y"}, "b": {"z"}}, cfg.Routes)
+	assert.Equal(t, map[string][]int{"a": {1, 2}, "b": {3}}, cfg.Ports)
+	assert.Equal(t, [][]int{{1, 2}, {3, 4, 5}}, cfg.Matrix)
+	assert.Equal(t, [][][]string{{{"a", "b"}, {"c"}}, {{"d"}}}, cfg.Deep)
+	assert.Equal(t, []map[string]float64{{"a": 1.5, "b": 2}, {"c": 3}}, cfg.Weights)
+	assert.Equal(t, map[string]map[string]int{"a": {"x": 1, "y": 2}, "b": {"z": 3}}, cfg.Nested)
+	require.Len(t, cfg.PtrItems, 2)
+	assert.Equal(t, 3, *cfg.PtrItems[1][0])
+	assert.Equal(t, map[string]map[string]bool{"a": {"x": true, "y": true}, "b": {"z": true}}, cfg.Sets)
+}
+
+func TestLoad_Sets(t *testing.T) {
+	type config struct {
+		Tags     map[string]struct{}
+		Ids      map[int]struct{} `env:"delim=;"`
+		Flags    map[string]bool
+		Explicit map[string]bool
+		Empty    map[string]struct{} `env:"optional"`
+	}
+	cfg := &config{}
+	err := Load(cfg, MapEnvReader{
+		"TAGS":     "a,b,a",
+		"IDS":      "1;2",
+		"FLAGS":    "x,y",
+		"EXPLICIT": "x:true,y:false,z",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]struct{}{"a": {}, "b": {}}, cfg.Tags)
+	assert.Equal(t, map[int]struct{}{1: {}, 2: {}}, cfg.Ids)
+	assert.Equal(t, map[string]bool{"x": true, "y": true}, cfg.Flags)
+	assert.Equal(t, map[string]bool{"x": true, "y": false, "z": true}, cfg.Explicit)
+	assert.Nil(t, cfg.Empty)
+}
+
+func TestLoad_NestedCollections_Errors(t *testing.T) {
+	testCases := []struct {
+		cfg         any
+		env         MapEnvReader
+		expectError string
+	}{
+		{
+			cfg: &struct {
+				Test [][]int
+			}{},
+			expectError: "field 'Test' has nested collection type (use env tag 'delims' with a delimiter for each of the 2 levels)",
+		},
+		{
+			cfg: &struct {
+				Test [][][]int `env:"delims=';,'"`
+			}{},
+			expectError: "field 'Test' has nested collection type (use env tag 'delims' with a delimiter for each of the 3 levels)",
+		},
+		{
+			cfg: &struct {
+				Test [][]int `env:"delims"`
+			}{},
+			expectError: "cannot use env tag 'delims' without value on field 'Test' (use quotes if necessary)",
+		},
+		{
+			cfg: &struct {
+				Test [][]chan int `env:"delims=';,'"`
+			}{},
+			expectError: "field 'Test' has unsupported slice item type",
+		},
+		{
+			cfg: &struct {
+				Test map[string]map[chan int]int `env:"delims=';,'"`
+			}{},
+			expectError: "field 'Test' has unsupported map item type",
+		},
+		{
+			cfg: &struct {
+				Test [][]int `env:"delims=';,'"`
+			}{},
+			env:         MapEnvReader{"TEST": "1,x;2"},
+			expectError: "env var 'TEST' is not an int",
+		},
+		{
+			cfg: &struct {
+				Test map[string][]int `env:"delims=';,'"`
+			}{},
+			env:         MapEnvReader{"TEST": "a:1;b"},
+			expectError: "env var 'TEST' contains invalid key/value pair - b",
+		},
+		{
+			cfg: &struct {
+				Test map[int]struct{}
+			}{},
+			env:         MapEnvReader{"TEST": "1,x"},
+			expectError: "env var 'TEST' is not an int",
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("[%d]", i+1), func(t *testing.T) {
+			env := tc.env
+			if env == nil {
+				env = MapEnvReader{}
+			}
+			err := Load(tc.cfg, env)
+			require.Error(t, err)
+			assert.Equal(t, tc.expectError, err.Error())
+		})
+	}
+}
+
+func TestWrite_NestedCollections(t *testing.T) {
+	type config struct {
+		Ports  map[string][]int `env:"delims=';,'"`
+		Matrix [][]int          `env:"delims=';,'"`
+		Tags   map[string]struct{}
+	}
+	cfg := &config{
+		Ports:  map[string][]int{"a": {1, 2}},
+		Matrix: [][]int{{1, 2}, {3}},
+		Tags:   map[string]struct{}{"x": {}},
+	}
+	var w bytes.Buffer
+	err := Write(&w, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "PORTS=a:1,2\nMATRIX=1,2;3\nTAGS=x\n", w.String())
+
+	w.Reset()
+	err = Example(&w, &config{})
+	require.NoError(t, err)
+	assert.Equal(t, "PORTS=key:value;key:value;...\nMATRIX=value;value;...\nTAGS=key,key,...\n", w.String())
+}
+
+func TestLoad_Quoted(t *testing.T) {
+	type config struct {
+		Names   []string            `env:"quoted"`
+		Escaped []string            `env:"quoted"`
+		Labels  map[string]string   `env:"quoted"`
+		Routes  map[string][]string `env:"quoted,delims=';,'"`
+		Plain   []string
+	}
+	cfg := &config{}
+	err := Load(cfg, MapEnvReader{
+		"NAMES":   `"a,b",c,"say \"hi\""`,
+		"ESCAPED": `"a,b","c\\",d\e`,
+		"LABELS":  `"x:y":"1,2",z:3`,
+		"ROUTES":  `a:"x;y",z;b:w`,
+		"PLAIN":   `"a,b",c`,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a,b", "c", `say "hi"`}, cfg.Names)
+	assert.Equal(t, []string{"a,b", `c\`, `d\e`}, cfg.Escaped)
+	assert.Equal(t, map[string]string{"x:y": "1,2", "z": "3"}, cfg.Labels)
+	assert.Equal(t, map[string][]string{"a": {"x;y", "z"}, "b": {"w"}}, cfg.Routes)
+	assert.Equal(t, []string{`"a`, `b"`, "c"}, cfg.Plain)
+}
+
+func TestLoad_QuotedOption(t *testing.T) {
+	type config struct {
+		Names []string
+		Raw   []string `env:"no-quoted"`
+	}
+	cfg := &config{}
+	err := Load(cfg, NewQuoted(), MapEnvReader{
+		"NAMES": `"a,b",c`,
+		"RAW":   `"a,b",c`,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a,b", "c"}, cfg.Names)
+	assert.Equal(t, []string{`"a`, `b"`, "c"}, cfg.Raw)
+
+	err = Load(cfg, NewQuoted(), NewQuoted())
+	require.Error(t, err)
+	assert.Equal(t, "multiple quoted options", err.Error())
+}
+
+func TestLoad_Quoted_Errors(t *testing.T) {
+	type config struct {
+		Names []string          `env:"quoted"`
+		Map   map[string]string `env:"quoted,optional"`
+	}
+	err := Load(&config{}, MapEnvReader{
+		"NAMES": `"a,b`,
+		"MAP":   `a:"b`,
+	})
+	require.Error(t, err)
+	errs := err.(*LoadErrors).Errors
+	require.Len(t, errs, 2)
+	assert.Equal(t, "env var 'NAMES' contains unterminated quote", errs[0].Error())
+	assert.Equal(t, "env var 'MAP' contains unterminated quote", errs[1].Error())
+}
+
+func TestWrite_Quoted_RoundTrip(t *testing.T) {
+	type config struct {
+		Names  []string            `env:"quoted"`
+		Labels map[string]string   `env:"quoted"`
+		Routes map[string][]string `env:"quoted,delims=';,'"`
+		Quotes []string            `env:"quoted"`
+	}
+	cfg := &config{
+		Names:  []string{"a,b", `say "hi"`, `c\d`},
+		Labels: map[string]string{"x:y": "1,2"},
+		Routes: map[string][]string{"a": {"x;y", "z"}},
+		Quotes: []string{`"a"`, `"b"`},
+	}
+	var w bytes.Buffer
+	err := Write(&w, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, `NAMES='"a,b","say \"hi\"","c\\d"'
+LABELS='"x:y":"1,2"'
+ROUTES=a:"x;y",z
+QUOTES='"\"a\"","\"b\""'
+`, w.String())
+
+	loaded := &config{}
+	err = Load(loaded, NewEnvFileReader(&w, nil))
+	require.NoError(t, err)
+	assert.Equal(t, cfg, loaded)
+}
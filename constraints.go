@@ -0,0 +1,194 @@
+package cfgenv
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// checkConstraints checks the cross-field constraints (required_if and groups) of the fields loaded for a struct
+func checkConstraints(loaded []*loadedField, prefix string, options *opts) []error {
+	result := make([]error, 0)
+	groups := make([]*constraintGroup, 0)
+	for _, lf := range loaded {
+		if lf.fi.requiredIf != "" && !lf.present && !lf.fi.hasDefault {
+			condName := addPrefixes(prefix, lf.fi.requiredIfName, options.separator.GetSeparator())
+			if conditionMet(condName, prefix, lf.fi, loaded, options) {
+				rule := condName
+				if lf.fi.requiredIfHasValue {
+					rule += "=" + lf.fi.requiredIfValue
+				}
+				result = append(result, &ConstraintError{
+					Names:  []string{lf.name, condName},
+					Fields: []string{lf.path},
+					Rule:   tokenRequiredIf + "=" + lf.fi.requiredIf,
+					msg:    fmt.Sprintf("missing env var '%s' (required when '%s')", lf.name, rule),
+				})
+			}
+		}
+		if lf.fi.group != "" {
+			grp := findConstraintGroup(&groups, lf.fi.group)
+			grp.add(lf)
+		}
+	}
+	for _, grp := range groups {
+		result = append(result, grp.check()...)
+	}
+	return result
+}
+
+// conditionMet determines whether a required_if condition is met
+//
+// without a value (e.g. `env:"required_if=TLS_ENABLED"`) the condition is met if the env var is present - otherwise the
+// condition is met if the env var value (or the default of the loaded field for that env var) equals the value
+func conditionMet(name string, prefix string, fi *fieldInfo, loaded []*loadedField, options *opts) bool {
+	raw, ok := lookupCondition(name, prefix, loaded, options)
+	if !fi.requiredIfHasValue {
+		return ok
+	}
+	for _, lf := range loaded {
+		if !ok && lf.name == name && lf.fi.hasDefault {
+			raw, ok = lf.fi.defaultValue, true
+		}
+	}
+	return ok && conditionValuesEqual(raw, fi.requiredIfValue)
+}
+
+// lookupCondition looks up the env var of a required_if condition - in the same way as the loaded field for that env var
+// (if any), so that aliases, file vars, expansion and decoding all apply
+//
+// the env var is not present if it cannot be read or decoded
+func lookupCondition(name string, prefix string, loaded []*loadedField, options *opts) (string, bool) {
+	fld, fi := reflect.StructField{Name: name, Type: reflect.TypeOf("")}, &fieldInfo{}
+	for _, lf := range loaded {
+		if lf.name == name {
+			fld, fi = lf.fld, lf.fi
+			break
+		}
+	}
+	raw, ok, err := options.lookupEnv(name, prefix, "", fld, fi)
+	if err == nil && ok {
+		raw, err = options.decode(name, raw, fld, fi)
+	}
+	return raw, ok && err == nil
+}
+
+func conditionValuesEqual(v string, expect string) bool {
+	if bv, err := strconv.ParseBool(v); err == nil {
+		if be, err := strconv.ParseBool(expect); err == nil {
+			return bv == be
+		}
+	}
+	return v == expect
+}
+
+type constraintGroup struct {
+	name         string
+	exclusive    bool
+	atLeastOne   bool
+	alternatives []*constraintAlternative
+}
+
+// constraintAlternative is a member of a group - fields tagged with the same group and member (e.g. `env:"group=auth:basic"`)
+// form one alternative, which is set if any of its fields are set (and all of its fields must then be set)
+type constraintAlternative struct {
+	member string
+	fields []*loadedField
+}
+
+func findConstraintGroup(groups *[]*constraintGroup, name string) *constraintGroup {
+	for _, grp := range *groups {
+		if grp.name == name {
+			return grp
+		}
+	}
+	grp := &constraintGroup{name: name}
+	*groups = append(*groups, grp)
+	return grp
+}
+
+func (grp *constraintGroup) add(lf *loadedField) {
+	grp.exclusive = grp.exclusive || lf.fi.exclusive
+	grp.atLeastOne = grp.atLeastOne || lf.fi.atLeastOne
+	if lf.fi.groupMember != "" {
+		for _, alt := range grp.alternatives {
+			if alt.member == lf.fi.groupMember {
+				alt.fields = append(alt.fields, lf)
+				return
+			}
+		}
+	}
+	grp.alternatives = append(grp.alternatives, &constraintAlternative{
+		member: lf.fi.groupMember,
+		fields: []*loadedField{lf},
+	})
+}
+
+func (grp *constraintGroup) check() []error {
+	result := make([]error, 0)
+	set := make([]*constraintAlternative, 0, len(grp.alternatives))
+	for _, alt := range grp.alternatives {
+		missing := make([]string, 0, len(alt.fields))
+		for _, lf := range alt.fields {
+			if !lf.present {
+				missing = append(missing, "'"+lf.name+"'")
+			}
+		}
+		if len(missing) < len(alt.fields) {
+			set = append(set, alt)
+			if len(missing) > 0 {
+				result = append(result, grp.newError([]*constraintAlternative{alt},
+					fmt.Sprintf("env vars %s must all be set (group '%s') - missing %s", alt.String(), grp.name, strings.Join(missing, ", "))))
+			}
+		}
+	}
+	if grp.exclusive && len(set) > 1 {
+		result = append(result, grp.newError(set,
+			fmt.Sprintf("only one of env vars %s may be set (group '%s')", alternativesString(grp.alternatives), grp.name)))
+	}
+	if grp.atLeastOne && len(set) == 0 {
+		result = append(result, grp.newError(grp.alternatives,
+			fmt.Sprintf("at least one of env vars %s must be set (group '%s')", alternativesString(grp.alternatives), grp.name)))
+	}
+	return result
+}
+
+func (grp *constraintGroup) newError(alts []*constraintAlternative, msg string) error {
+	rule := tokenGroup + "=" + grp.name
+	if grp.exclusive {
+		rule += "," + tokenExclusive
+	}
+	if grp.atLeastOne {
+		rule += "," + tokenAtLeastOne
+	}
+	result := &ConstraintError{
+		Names:  make([]string, 0),
+		Fields: make([]string, 0),
+		Rule:   rule,
+		msg:    msg,
+	}
+	for _, alt := range alts {
+		for _, lf := range alt.fields {
+			result.Names = append(result.Names, lf.name)
+			result.Fields = append(result.Fields, lf.path)
+		}
+	}
+	return result
+}
+
+func (alt *constraintAlternative) String() string {
+	names := make([]string, 0, len(alt.fields))
+	for _, lf := range alt.fields {
+		names = append(names, lf.name)
+	}
+	return "'" + strings.Join(names, "+") + "'"
+}
+
+func alternativesString(alts []*constraintAlternative) string {
+	strs := make([]string, 0, len(alts))
+	for _, alt := range alts {
+		strs = append(strs, alt.String())
+	}
+	return strings.Join(strs, ", ")
+}
@@ -0,0 +1,209 @@
+package cfgenv
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestLoad_RequiredIf(t *testing.T) {
+	type tlsConfig struct {
+		TlsEnabled bool   `env:"optional,default=false"`
+		TlsCert    string `env:"required_if='TLS_ENABLED=true'"`
+		TlsKey     string `env:"required_if=TLS_ENABLED"`
+	}
+	type config struct {
+		Server tlsConfig `env:"prefix=SERVER"`
+	}
+	testCases := []struct {
+		env         map[string]string
+		expectError string
+	}{
+		{},
+		{
+			env:         map[string]string{"SERVER_TLS_ENABLED": "false"},
+			expectError: `missing env var 'SERVER_TLS_KEY' (required when 'SERVER_TLS_ENABLED')`,
+		},
+		{
+			env: map[string]string{"SERVER_TLS_ENABLED": "1"},
+			expectError: `missing env var 'SERVER_TLS_CERT' (required when 'SERVER_TLS_ENABLED=true')
+missing env var 'SERVER_TLS_KEY' (required when 'SERVER_TLS_ENABLED')`,
+		},
+		{
+			env: map[string]string{"SERVER_TLS_ENABLED": "true", "SERVER_TLS_CERT": "cert", "SERVER_TLS_KEY": "key"},
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("[%d]", i+1), func(t *testing.T) {
+			err := Load(&config{}, MapEnvReader(tc.env))
+			if tc.expectError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectError, err.Error())
+			}
+		})
+	}
+}
+
+func TestLoad_RequiredIf_WithDefault(t *testing.T) {
+	type config struct {
+		TlsEnabled bool   `env:"optional,default=true"`
+		TlsCert    string `env:"required_if='TLS_ENABLED=true'"`
+	}
+	err := Load(&config{}, MapEnvReader{})
+	require.Error(t, err)
+	var ce *ConstraintError
+	require.True(t, errors.As(err, &ce))
+	assert.Equal(t, []string{"TLS_CERT", "TLS_ENABLED"}, ce.Names)
+	assert.Equal(t, []string{"TlsCert"}, ce.Fields)
+	assert.Equal(t, "required_if=TLS_ENABLED=true", ce.Rule)
+	assert.Nil(t, ce.Unwrap())
+}
+
+func TestLoad_RequiredIf_ConditionLookup(t *testing.T) {
+	type config struct {
+		Mode    string `env:"optional,alias=APP_MODE,encoding=base64"`
+		TlsCert string `env:"required_if='MODE=tls'"`
+		TlsKey  string `env:"required_if=MODE"`
+	}
+	testCases := []struct {
+		env     MapEnvReader
+		options []any
+	}{
+		{env: MapEnvReader{"MODE_FILE": writeTestFile(t, "mode", "dGxz\n")}, options: []any{NewFileVars(true)}},
+		{env: MapEnvReader{"APP_MODE": "dGxz"}},
+		{env: MapEnvReader{"MODE": "dGxz"}},
+		{env: MapEnvReader{"MODE": "${ENCODED}", "ENCODED": "dGxz"}, options: []any{Expand()}},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("[%d]", i+1), func(t *testing.T) {
+			cfg := &config{}
+			err := Load(cfg, append(tc.options, tc.env)...)
+			require.Error(t, err)
+			assert.Equal(t, `missing env var 'TLS_CERT' (required when 'MODE=tls')
+missing env var 'TLS_KEY' (required when 'MODE')`, err.Error())
+			assert.Equal(t, "tls", cfg.Mode)
+		})
+	}
+
+	// condition env var not a field - file vars still apply...
+	type other struct {
+		TlsCert string `env:"required_if=MODE"`
+	}
+	err := Load(&other{}, NewFileVars(true), MapEnvReader{"MODE_FILE": writeTestFile(t, "mode2", "tls")})
+	require.Error(t, err)
+	assert.Equal(t, `missing env var 'TLS_CERT' (required when 'MODE')`, err.Error())
+}
+
+func TestLoad_Groups(t *testing.T) {
+	type authConfig struct {
+		ApiKey   string `env:"group=auth,exclusive,atleastone"`
+		Username string `env:"group=auth:basic"`
+		Password string `env:"group=auth:basic"`
+	}
+	type config struct {
+		Auth   authConfig `env:"prefix=AUTH"`
+		Kafka  string     `env:"group=sink,atleastone"`
+		Stdout *bool      `env:"group=sink"`
+	}
+	testCases := []struct {
+		env         map[string]string
+		expectError string
+	}{
+		{
+			expectError: `at least one of env vars 'AUTH_API_KEY', 'AUTH_USERNAME+AUTH_PASSWORD' must be set (group 'auth')
+at least one of env vars 'KAFKA', 'STDOUT' must be set (group 'sink')`,
+		},
+		{
+			env: map[string]string{"AUTH_API_KEY": "key", "STDOUT": "true"},
+		},
+		{
+			env: map[string]string{"AUTH_USERNAME": "user", "AUTH_PASSWORD": "pwd", "KAFKA": "kafka", "STDOUT": "true"},
+		},
+		{
+			env:         map[string]string{"AUTH_USERNAME": "user", "KAFKA": "kafka"},
+			expectError: `env vars 'AUTH_USERNAME+AUTH_PASSWORD' must all be set (group 'auth') - missing 'AUTH_PASSWORD'`,
+		},
+		{
+			env:         map[string]string{"AUTH_API_KEY": "key", "AUTH_USERNAME": "user", "AUTH_PASSWORD": "pwd", "KAFKA": "kafka"},
+			expectError: `only one of env vars 'AUTH_API_KEY', 'AUTH_USERNAME+AUTH_PASSWORD' may be set (group 'auth')`,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("[%d]", i+1), func(t *testing.T) {
+			err := Load(&config{}, MapEnvReader(tc.env))
+			if tc.expectError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectError, err.Error())
+			}
+		})
+	}
+}
+
+func TestLoad_Groups_ConstraintError(t *testing.T) {
+	type base struct {
+		ApiKey string `env:"group=auth,exclusive"`
+	}
+	type config struct {
+		base
+		Username string `env:"group=auth:basic"`
+		Password string `env:"group=auth:basic"`
+	}
+	err := Load(&config{}, MapEnvReader{"API_KEY": "key", "USERNAME": "user", "PASSWORD": "pwd"}, NewFailFast())
+	require.Error(t, err)
+	ce, ok := err.(*ConstraintError)
+	require.True(t, ok)
+	assert.Equal(t, []string{"API_KEY", "USERNAME", "PASSWORD"}, ce.Names)
+	assert.Equal(t, []string{"ApiKey", "Username", "Password"}, ce.Fields)
+	assert.Equal(t, "group=auth,exclusive", ce.Rule)
+}
+
+func TestLoad_ConstraintTagErrors(t *testing.T) {
+	testCases := []struct {
+		cfg         any
+		expectError string
+	}{
+		{
+			cfg: &struct {
+				Test string `env:"exclusive"`
+			}{},
+			expectError: "cannot use env tags 'exclusive' or 'atleastone' without 'group' on field 'Test'",
+		},
+		{
+			cfg: &struct {
+				Test struct{} `env:"group=foo"`
+			}{},
+			expectError: "cannot use env tags 'group' or 'required_if' on struct field 'Test'",
+		},
+		{
+			cfg: &struct {
+				Test string `env:"group=':foo'"`
+			}{},
+			expectError: "env tag 'group' on field 'Test' - missing group name",
+		},
+		{
+			cfg: &struct {
+				Test string `env:"required_if='=foo'"`
+			}{},
+			expectError: "env tag 'required_if' on field 'Test' - missing env var name",
+		},
+		{
+			cfg: &struct {
+				Test string `env:"required_if"`
+			}{},
+			expectError: "cannot use env tag 'required_if' without value on field 'Test' (use quotes if necessary)",
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("[%d]", i+1), func(t *testing.T) {
+			err := Load(tc.cfg, MapEnvReader{})
+			assert.Error(t, err)
+			assert.Equal(t, tc.expectError, err.Error())
+		})
+	}
+}
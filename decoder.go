@@ -1,6 +1,9 @@
 package cfgenv
 
-import "encoding/base64"
+import (
+	"encoding/base64"
+	"reflect"
+)
 
 const (
 	encodingBase64       = "base64"
@@ -24,6 +27,25 @@ type Decoder interface {
 	Decode(value string) (string, error)
 }
 
+// byteArrayEncodings are the encodings of the built-in Decoders - used to re-encode byte array fields when written (see Write)
+var byteArrayEncodings = map[string]*base64.Encoding{
+	encodingBase64:       base64.StdEncoding,
+	encodingBase64Url:    base64.URLEncoding,
+	encodingRawBase64:    base64.RawStdEncoding,
+	encodingRawBase64Url: base64.RawURLEncoding,
+}
+
+// encodeByteArray returns the value of a byte array field re-encoded with the field's (built-in) encoding - byte arrays
+// are rarely printable so, unlike other fields, are not written decoded
+func (fi *fieldInfo) encodeByteArray(fv reflect.Value, value string) string {
+	if fi.decoder != nil && fv.Kind() == reflect.Array && fv.Type().Elem().Kind() == reflect.Uint8 {
+		if enc, ok := byteArrayEncodings[fi.decoder.Encoding()]; ok {
+			return enc.EncodeToString([]byte(value))
+		}
+	}
+	return value
+}
+
 // NewBase64Decoder returns a new Decoder for decoding base64
 func NewBase64Decoder() Decoder {
 	return &base64Decoder{}
@@ -0,0 +1,77 @@
+package cfgenv
+
+import (
+	"github.com/go-andiamo/gopt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// ExtendedDurationsOption is an option that can be passed to Load or LoadAs
+// and determines whether time.Duration values also accept the units "d" (days) and "w" (weeks), e.g. "1w2d" or "1.5d"
+//
+// Fields can also use the `extended` env tag
+type ExtendedDurationsOption interface {
+	// ExtendedDurations returns whether time.Duration values also accept the units "d" (days) and "w" (weeks)
+	ExtendedDurations() bool
+}
+
+type extendedDurationsOpt struct{}
+
+func (e *extendedDurationsOpt) ExtendedDurations() bool {
+	return true
+}
+
+// NewExtendedDurations creates a new ExtendedDurationsOption - where time.Duration values also accept the units "d" (days)
+// and "w" (weeks)
+func NewExtendedDurations() ExtendedDurationsOption {
+	return &extendedDurationsOpt{}
+}
+
+var extendedDurationUnits = regexp.MustCompile(`(\d+(?:\.\d*)?|\.\d+)([dw])`)
+
+// parseDuration parses a duration using time.ParseDuration - if extended, the units "d" (24h) and "w" (168h) are also accepted
+func parseDuration(raw string, extended bool) (time.Duration, error) {
+	if extended {
+		raw = extendedDurationUnits.ReplaceAllStringFunc(raw, func(s string) string {
+			hours := 24.0
+			if s[len(s)-1] == 'w' {
+				hours = 7 * 24
+			}
+			n, _ := strconv.ParseFloat(s[:len(s)-1], 64)
+			return strconv.FormatFloat(n*hours, 'f', -1, 64) + "h"
+		})
+	}
+	return time.ParseDuration(raw)
+}
+
+func setDurationValue(name string, raw string, fv reflect.Value, isPtr bool, extended bool) error {
+	if d, err := parseDuration(raw, extended); err == nil {
+		if isPtr {
+			fv.Set(reflect.ValueOf(&d))
+		} else {
+			fv.Set(reflect.ValueOf(d))
+		}
+		return nil
+	} else {
+		return &ParseError{Name: name, Type: fv.Type(), Err: err, msg: "is not a duration"}
+	}
+}
+
+// optionalDurationSetter creates an optionalSetterFn for gopt.Optional[time.Duration]
+func optionalDurationSetter(extended bool) optionalSetterFn {
+	return func(v reflect.Value, raw string, present bool) error {
+		d, err := parseDuration(raw, extended)
+		if err == nil {
+			if present {
+				av := gopt.Empty[time.Duration]().WasSetElseSet(d)
+				v.Set(reflect.ValueOf(*av))
+			} else {
+				av := gopt.Of[time.Duration](d)
+				v.Set(reflect.ValueOf(*av))
+			}
+		}
+		return err
+	}
+}
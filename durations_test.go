@@ -0,0 +1,156 @@
+package cfgenv
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/go-andiamo/gopt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestLoad_Durations(t *testing.T) {
+	type config struct {
+		Timeout  time.Duration
+		Ptr      *time.Duration
+		Items    []time.Duration
+		Ptrs     []*time.Duration
+		Map      map[string]time.Duration
+		Keys     map[time.Duration]string
+		Optional gopt.Optional[time.Duration]
+		Default  gopt.Optional[time.Duration] `env:"default=1m"`
+		Indexed  []time.Duration
+	}
+	cfg := &config{}
+	err := Load(cfg, MapEnvReader{
+		"TIMEOUT":   "5m",
+		"PTR":       "1h30m",
+		"ITEMS":     "1s,2ms",
+		"PTRS":      "3s",
+		"MAP":       "read:5s,write:10s",
+		"KEYS":      "1m:short,1h:long",
+		"OPTIONAL":  "250ms",
+		"INDEXED_0": "1s",
+		"INDEXED_1": "2s",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, cfg.Timeout)
+	assert.Equal(t, 90*time.Minute, *cfg.Ptr)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Millisecond}, cfg.Items)
+	assert.Equal(t, 3*time.Second, *cfg.Ptrs[0])
+	assert.Equal(t, map[string]time.Duration{"read": 5 * time.Second, "write": 10 * time.Second}, cfg.Map)
+	assert.Equal(t, map[time.Duration]string{time.Minute: "short", time.Hour: "long"}, cfg.Keys)
+	assert.Equal(t, 250*time.Millisecond, cfg.Optional.Default(0))
+	assert.True(t, cfg.Optional.WasSet())
+	assert.Equal(t, time.Minute, cfg.Default.Default(0))
+	assert.False(t, cfg.Default.WasSet())
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, cfg.Indexed)
+}
+
+func TestLoad_ExtendedDurations(t *testing.T) {
+	type config struct {
+		Retention time.Duration                `env:"extended"`
+		Items     []time.Duration              `env:"extended"`
+		Optional  gopt.Optional[time.Duration] `env:"extended"`
+	}
+	cfg := &config{}
+	err := Load(cfg, MapEnvReader{
+		"RETENTION": "1w2d3h",
+		"ITEMS":     "1.5d,.5w,-1d",
+		"OPTIONAL":  "2d",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 9*24*time.Hour+3*time.Hour, cfg.Retention)
+	assert.Equal(t, []time.Duration{36 * time.Hour, 84 * time.Hour, -24 * time.Hour}, cfg.Items)
+	assert.Equal(t, 48*time.Hour, cfg.Optional.Default(0))
+
+	plain := &struct {
+		Plain time.Duration
+	}{}
+	err = Load(plain, MapEnvReader{"PLAIN": "1d"})
+	require.Error(t, err)
+	assert.Equal(t, "env var 'PLAIN' is not a duration", err.Error())
+
+	err = Load(plain, NewExtendedDurations(), MapEnvReader{"PLAIN": "1d"})
+	require.NoError(t, err)
+	assert.Equal(t, 24*time.Hour, plain.Plain)
+
+	err = Load(plain, NewExtendedDurations(), NewExtendedDurations())
+	require.Error(t, err)
+	assert.Equal(t, "multiple extended durations options", err.Error())
+}
+
+func TestLoad_Durations_Errors(t *testing.T) {
+	testCases := []struct {
+		cfg         any
+		env         MapEnvReader
+		expectError string
+	}{
+		{
+			cfg: &struct {
+				Test time.Duration
+			}{},
+			env:         MapEnvReader{"TEST": "1000"},
+			expectError: "env var 'TEST' is not a duration",
+		},
+		{
+			cfg: &struct {
+				Test *time.Duration
+			}{},
+			env:         MapEnvReader{"TEST": "x"},
+			expectError: "env var 'TEST' is not a duration",
+		},
+		{
+			cfg: &struct {
+				Test []time.Duration
+			}{},
+			env:         MapEnvReader{"TEST": "1s,1d"},
+			expectError: "env var 'TEST' is not a duration",
+		},
+		{
+			cfg: &struct {
+				Test gopt.Optional[time.Duration]
+			}{},
+			env:         MapEnvReader{"TEST": "1d"},
+			expectError: `env var 'TEST' is invalid: time: unknown unit "d" in duration "1d"`,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("[%d]", i+1), func(t *testing.T) {
+			err := Load(tc.cfg, tc.env)
+			require.Error(t, err)
+			assert.Equal(t, tc.expectError, err.Error())
+		})
+	}
+}
+
+func TestWrite_Durations(t *testing.T) {
+	type config struct {
+		Timeout time.Duration
+		Ptr     *time.Duration
+		Items   []time.Duration
+		Json    []time.Duration `env:"format=json"`
+	}
+	d := 90 * time.Minute
+	cfg := &config{
+		Timeout: 5 * time.Minute,
+		Ptr:     &d,
+		Items:   []time.Duration{time.Second, 2 * time.Millisecond},
+		Json:    []time.Duration{time.Second},
+	}
+	var w bytes.Buffer
+	err := Write(&w, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "TIMEOUT=5m0s\nPTR=1h30m0s\nITEMS=1s,2ms\nJSON=[\"1s\"]\n", w.String())
+
+	loaded := &config{}
+	err = Load(loaded, NewEnvFileReader(&w, nil))
+	require.NoError(t, err)
+	assert.Equal(t, cfg, loaded)
+
+	w.Reset()
+	err = Example(&w, &config{})
+	require.NoError(t, err)
+	assert.Equal(t, "TIMEOUT=0s\nPTR=<value>\nITEMS=value,value,...\nJSON=[\"value\",\"value\",...]\n", w.String())
+}
@@ -0,0 +1,167 @@
+package cfgenv
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// EnumOption is an option that can be passed to Load, LoadAs, Write, Example or ExampleOf
+// and maps env var values to the typed constants of an enum type (see Enum)
+//
+// Fields of the enum type (including pointers, slices, maps & gopt.Optional) are loaded from, and written as, the
+// names of the constants
+type EnumOption interface {
+	// EnumType returns the type of the enum constants
+	EnumType() reflect.Type
+	// Parse returns the constant for the name (names are matched case-insensitively) - and false if the name is not valid
+	Parse(name string) (any, bool)
+	// Format returns the name of the constant - and false if the value is not a known constant
+	Format(v any) (string, bool)
+	// Names returns the valid names (not including aliases)
+	Names() []string
+}
+
+type enumOpt[T comparable] struct {
+	names  []string
+	values map[string]T
+	byVal  map[T]string
+}
+
+// Enum creates a new EnumOption for the type T - where values maps the names to the constants, e.g.
+//
+//	cfgenv.Enum(map[string]LogLevel{"debug": Debug, "info": Info, "warn": Warn})
+//
+// any aliases are additional names accepted when loading (but never written), e.g.
+//
+//	cfgenv.Enum(map[string]LogLevel{"debug": Debug, "info": Info, "warn": Warn}, map[string]LogLevel{"warning": Warn})
+//
+// names are ordered by constant value (for numeric and string types) when listed in errors and examples
+func Enum[T comparable](values map[string]T, aliases ...map[string]T) EnumOption {
+	result := &enumOpt[T]{
+		names:  make([]string, 0, len(values)),
+		values: make(map[string]T, len(values)),
+		byVal:  make(map[T]string, len(values)),
+	}
+	for n, v := range values {
+		result.names = append(result.names, n)
+		result.values[strings.ToLower(n)] = v
+	}
+	sort.Slice(result.names, func(i, j int) bool {
+		if c := compareEnumValues(reflect.ValueOf(values[result.names[i]]), reflect.ValueOf(values[result.names[j]])); c != 0 {
+			return c < 0
+		}
+		return result.names[i] < result.names[j]
+	})
+	for _, n := range result.names {
+		if _, ok := result.byVal[values[n]]; !ok {
+			result.byVal[values[n]] = n
+		}
+	}
+	for _, am := range aliases {
+		for n, v := range am {
+			if _, ok := result.values[strings.ToLower(n)]; !ok {
+				result.values[strings.ToLower(n)] = v
+			}
+		}
+	}
+	return result
+}
+
+func (e *enumOpt[T]) EnumType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+func (e *enumOpt[T]) Parse(name string) (any, bool) {
+	v, ok := e.values[strings.ToLower(name)]
+	return v, ok
+}
+
+func (e *enumOpt[T]) Format(v any) (string, bool) {
+	if tv, ok := v.(T); ok {
+		n, ok := e.byVal[tv]
+		return n, ok
+	}
+	return "", false
+}
+
+func (e *enumOpt[T]) Names() []string {
+	return e.names
+}
+
+// compareEnumValues compares enum constants of numeric and string types (other types are considered equal)
+func compareEnumValues(a, b reflect.Value) int {
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return compare(a.Int(), b.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return compare(a.Uint(), b.Uint())
+	case reflect.Float32, reflect.Float64:
+		return compare(a.Float(), b.Float())
+	case reflect.String:
+		return strings.Compare(a.String(), b.String())
+	}
+	return 0
+}
+
+// enumOf returns the enum option (if any) for the type (or pointer to type)
+func (fi *fieldInfo) enumOf(t reflect.Type) EnumOption {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return fi.enums[t]
+}
+
+// setEnumValue sets a value of an enum type (or pointer to enum type)
+func setEnumValue(name string, raw string, fv reflect.Value, enum EnumOption) error {
+	if err := setEnum(raw, fv, enum); err != nil {
+		return &ParseError{Name: name, Type: fv.Type(), Err: err, msg: err.Error()}
+	}
+	return nil
+}
+
+// enumSetter returns a function that sets a value of the enum type (used for gopt.Optional enum types)
+func enumSetter(enum EnumOption) func(raw string, fv reflect.Value) error {
+	return func(raw string, fv reflect.Value) error {
+		return setEnum(raw, fv, enum)
+	}
+}
+
+func setEnum(raw string, fv reflect.Value, enum EnumOption) error {
+	v, ok := enum.Parse(raw)
+	if !ok {
+		return fmt.Errorf("value '%s' is not one of '%s'", raw, strings.Join(enum.Names(), "|"))
+	}
+	if fv.Kind() == reflect.Pointer {
+		pv := reflect.New(fv.Type().Elem())
+		pv.Elem().Set(reflect.ValueOf(v))
+		fv.Set(pv)
+	} else {
+		fv.Set(reflect.ValueOf(v))
+	}
+	return nil
+}
+
+// exampleEnum returns the enum option (if any) for an example of the field type (or pointer to type or optional type)
+func (fi *fieldInfo) exampleEnum(t reflect.Type) EnumOption {
+	if isOptionalType(t) {
+		t = optionalItemType(t)
+	}
+	return fi.enumOf(t)
+}
+
+// enumValueString returns the name of an enum value (or pointer to enum value) - and false if the value is not of an
+// enum type, is not a known constant or is a nil pointer
+func (fi *fieldInfo) enumValueString(v reflect.Value) (string, bool) {
+	enum := fi.enumOf(v.Type())
+	if enum == nil {
+		return "", false
+	} else if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "", false
+		}
+		v = v.Elem()
+	}
+	return enum.Format(v.Interface())
+}
@@ -0,0 +1,155 @@
+package cfgenv
+
+import (
+	"bytes"
+	"errors"
+	"github.com/go-andiamo/gopt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type testLogLevel int
+
+const (
+	testDebug testLogLevel = iota
+	testInfo
+	testWarn
+)
+
+type testColor string
+
+var testLogLevels = Enum(map[string]testLogLevel{
+	"debug": testDebug,
+	"info":  testInfo,
+	"warn":  testWarn,
+}, map[string]testLogLevel{
+	"warning": testWarn,
+})
+
+func TestEnum(t *testing.T) {
+	assert.Equal(t, []string{"debug", "info", "warn"}, testLogLevels.Names())
+	v, ok := testLogLevels.Parse("WARNING")
+	assert.True(t, ok)
+	assert.Equal(t, testWarn, v)
+	_, ok = testLogLevels.Parse("error")
+	assert.False(t, ok)
+	s, ok := testLogLevels.Format(testInfo)
+	assert.True(t, ok)
+	assert.Equal(t, "info", s)
+	_, ok = testLogLevels.Format(testLogLevel(99))
+	assert.False(t, ok)
+	_, ok = testLogLevels.Format(1)
+	assert.False(t, ok)
+
+	colors := Enum(map[string]testColor{"red": "r", "green": "g", "blue": "b", "azure": "b"})
+	assert.Equal(t, []string{"azure", "blue", "green", "red"}, colors.Names())
+	s, _ = colors.Format(testColor("b"))
+	assert.Equal(t, "azure", s)
+}
+
+func TestLoad_Enums(t *testing.T) {
+	type config struct {
+		Level    testLogLevel
+		Alias    testLogLevel
+		Ptr      *testLogLevel
+		Levels   []testLogLevel
+		ByName   map[string]testLogLevel
+		ByLevel  map[testLogLevel]testColor
+		Optional gopt.Optional[testLogLevel]
+		Default  gopt.Optional[testLogLevel] `env:"default=warn"`
+		Plain    int
+	}
+	cfg := &config{}
+	err := Load(cfg, testLogLevels, Enum(map[string]testColor{"red": "r", "green": "g"}), MapEnvReader{
+		"LEVEL":    "Info",
+		"ALIAS":    "warning",
+		"PTR":      "DEBUG",
+		"LEVELS":   "debug,warn",
+		"BY_NAME":  "a:info,b:warn",
+		"BY_LEVEL": "debug:red,warn:green",
+		"OPTIONAL": "info",
+		"PLAIN":    "1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, testInfo, cfg.Level)
+	assert.Equal(t, testWarn, cfg.Alias)
+	assert.Equal(t, testDebug, *cfg.Ptr)
+	assert.Equal(t, []testLogLevel{testDebug, testWarn}, cfg.Levels)
+	assert.Equal(t, map[string]testLogLevel{"a": testInfo, "b": testWarn}, cfg.ByName)
+	assert.Equal(t, map[testLogLevel]testColor{testDebug: "r", testWarn: "g"}, cfg.ByLevel)
+	assert.Equal(t, testInfo, cfg.Optional.Default(testDebug))
+	assert.Equal(t, testWarn, cfg.Default.Default(testDebug))
+	assert.Equal(t, 1, cfg.Plain)
+}
+
+func TestLoad_Enums_Errors(t *testing.T) {
+	type config struct {
+		Level    testLogLevel
+		Levels   []testLogLevel
+		Optional gopt.Optional[testLogLevel]
+	}
+	err := Load(&config{}, testLogLevels, MapEnvReader{
+		"LEVEL":    "1",
+		"LEVELS":   "debug,error",
+		"OPTIONAL": "x",
+	})
+	require.Error(t, err)
+	errs := err.(*LoadErrors).Errors
+	require.Len(t, errs, 3)
+	assert.Equal(t, "env var 'LEVEL' value '1' is not one of 'debug|info|warn'", errs[0].Error())
+	assert.Equal(t, "env var 'LEVELS' value 'error' is not one of 'debug|info|warn'", errs[1].Error())
+	assert.Equal(t, "env var 'OPTIONAL' is invalid: value 'x' is not one of 'debug|info|warn'", errs[2].Error())
+	var pe *ParseError
+	assert.True(t, errors.As(errs[0], &pe))
+
+	err = Load(&config{}, testLogLevels, Enum(map[string]testLogLevel{"x": testDebug}))
+	require.Error(t, err)
+	assert.Equal(t, "multiple enum options for type cfgenv.testLogLevel", err.Error())
+}
+
+func TestWrite_Enums(t *testing.T) {
+	type config struct {
+		Level   testLogLevel
+		Unknown testLogLevel
+		Ptr     *testLogLevel
+		Levels  []testLogLevel
+		ByLevel map[testLogLevel]int
+		Json    []testLogLevel `env:"format=json"`
+	}
+	lvl := testWarn
+	cfg := &config{
+		Level:   testInfo,
+		Unknown: testLogLevel(99),
+		Ptr:     &lvl,
+		Levels:  []testLogLevel{testDebug, testWarn},
+		ByLevel: map[testLogLevel]int{testInfo: 1},
+		Json:    []testLogLevel{testInfo},
+	}
+	var w bytes.Buffer
+	err := Write(&w, cfg, testLogLevels)
+	require.NoError(t, err)
+	assert.Equal(t, `LEVEL=info
+UNKNOWN=99
+PTR=warn
+LEVELS=debug,warn
+BY_LEVEL=info:1
+JSON=["info"]
+`, w.String())
+
+	w.Reset()
+	err = Example(&w, &struct {
+		Level    testLogLevel
+		Ptr      *testLogLevel
+		Optional gopt.Optional[testLogLevel]
+		Default  testLogLevel `env:"default=info"`
+		Levels   []testLogLevel
+	}{}, testLogLevels)
+	require.NoError(t, err)
+	assert.Equal(t, `LEVEL=debug|info|warn
+PTR=debug|info|warn
+OPTIONAL=debug|info|warn
+DEFAULT=info
+LEVELS=value,value,...
+`, w.String())
+}
@@ -0,0 +1,107 @@
+package cfgenv
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+const (
+	keyCaseLower = "lower"
+	keyCaseUpper = "upper"
+	keyCaseCamel = "camel"
+)
+
+var keyCases = map[string]bool{
+	keyCaseLower: true,
+	keyCaseUpper: true,
+	keyCaseCamel: true,
+}
+
+// matchKeyGroup returns the index of the capture group used as the map key for a match map - the group named "key" or,
+// if there is no group named "key", the first named group (returns 0 if there are no named groups)
+func matchKeyGroup(rx *regexp.Regexp) int {
+	if i := rx.SubexpIndex("key"); i > 0 {
+		return i
+	}
+	for i, n := range rx.SubexpNames() {
+		if n != "" {
+			return i
+		}
+	}
+	return 0
+}
+
+// setEnvMap sets a prefix and/or match map from the env vars whose names have the prefix and/or match the regexp
+//
+// the map key is the env var name (less the prefix, or the named capture group of the match) with any key transforms applied,
+// and each map value is set from the env var value
+func setEnvMap(fv reflect.Value, prefix string, hasPrefix bool, fld reflect.StructField, fi *fieldInfo, options *opts) error {
+	sep := options.separator.GetSeparator()
+	m := reflect.MakeMap(fv.Type())
+	kt := fv.Type().Key()
+	vt := fv.Type().Elem()
+	for _, e := range options.reader.Environ() {
+		ev := strings.SplitN(e, "=", 2)
+		if len(ev) != 2 {
+			continue
+		}
+		key := ev[0]
+		if hasPrefix {
+			if !strings.HasPrefix(key, prefix) {
+				continue
+			}
+			key = key[len(prefix):]
+		}
+		if fi.matchRegex != nil {
+			sm := fi.matchRegex.FindStringSubmatch(key)
+			if sm == nil {
+				continue
+			} else if fi.matchKeyGroup > 0 {
+				key = sm[fi.matchKeyGroup]
+			}
+		}
+		raw, err := options.decode(ev[0], ev[1], fld, fi)
+		if err != nil {
+			return err
+		}
+		kv := reflect.New(kt).Elem()
+		if err = setValue(ev[0], fi.transformKey(key, sep), fld, fi, kv); err != nil {
+			return err
+		}
+		vv := reflect.New(vt).Elem()
+		if err = setItemValue(ev[0], raw, fld, fi, vv, 0); err != nil {
+			return err
+		}
+		m.SetMapIndex(kv, vv)
+	}
+	fv.Set(m)
+	return nil
+}
+
+// hasEnvMapNames determines whether the env var names of the items of a prefix/match map can be determined from the
+// map keys (for writing) - i.e. the keys are not transformed by the `keytrim` or `keycase` tags or a match capture group
+func (fi *fieldInfo) hasEnvMapNames() bool {
+	return fi.keyTrim == "" && fi.keyCase == "" && (fi.matchRegex == nil || fi.matchKeyGroup == 0)
+}
+
+// transformKey applies the key transforms (tags `keytrim` and `keycase`) to a prefix/match map key
+func (fi *fieldInfo) transformKey(key string, separator string) string {
+	key = strings.TrimSuffix(key, fi.keyTrim)
+	switch fi.keyCase {
+	case keyCaseLower:
+		key = strings.ToLower(key)
+	case keyCaseUpper:
+		key = strings.ToUpper(key)
+	case keyCaseCamel:
+		var sb strings.Builder
+		for i, pt := range strings.Split(strings.ToLower(key), separator) {
+			if i > 0 && pt != "" {
+				pt = strings.ToUpper(pt[:1]) + pt[1:]
+			}
+			sb.WriteString(pt)
+		}
+		key = sb.String()
+	}
+	return key
+}
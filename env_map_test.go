@@ -0,0 +1,243 @@
+package cfgenv
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestLoad_TypedEnvMaps(t *testing.T) {
+	type config struct {
+		Limits    map[string]int           `env:"prefix=LIMIT_"`
+		Timeouts  map[string]time.Duration `env:"prefix=TIMEOUT_"`
+		Hosts     map[string][]string      `env:"prefix=HOSTS_"`
+		Ptrs      map[string]*int          `env:"prefix=PTR_"`
+		Levels    map[string]testLevel     `env:"prefix=LEVEL_"`
+		Dates     map[string]time.Time     `env:"prefix=DATE_"`
+		Codes     map[int]string           `env:"match='^CODE_(?P<key>[0-9]+)$'"`
+		Enabled   map[string]bool          `env:"match='^FEATURE_(?P<key>.+)_ENABLED$',keycase=lower"`
+		Retries   map[string]int           `env:"prefix=RETRY_,keytrim=_COUNT,keycase=camel"`
+		Unmatched map[string]uint          `env:"prefix=NONE_"`
+	}
+	cfg := &config{}
+	err := Load(cfg, NewDatetimeSetter("2006-01-02"), NewDurationSetter(), MapEnvReader{
+		"LIMIT_CPU":                   "4",
+		"LIMIT_MEM":                   "1024",
+		"TIMEOUT_READ":                "5s",
+		"HOSTS_EU":                    "a,b",
+		"PTR_X":                       "1",
+		"LEVEL_API":                   "info",
+		"DATE_START":                  "2024-01-02",
+		"CODE_404":                    "not found",
+		"CODE_X":                      "ignored",
+		"FEATURE_DARK_MODE_ENABLED":   "true",
+		"FEATURE_BETA_ENABLED":        "false",
+		"RETRY_HTTP_CLIENT_COUNT":     "3",
+		"RETRY_DB_COUNT":              "5",
+		"FEATURE_DARK_MODE_SUPPORTED": "true",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"CPU": 4, "MEM": 1024}, cfg.Limits)
+	assert.Equal(t, map[string]time.Duration{"READ": 5 * time.Second}, cfg.Timeouts)
+	assert.Equal(t, map[string][]string{"EU": {"a", "b"}}, cfg.Hosts)
+	require.Len(t, cfg.Ptrs, 1)
+	assert.Equal(t, 1, *cfg.Ptrs["X"])
+	assert.Equal(t, map[string]testLevel{"API": testLevelInfo}, cfg.Levels)
+	assert.Equal(t, map[string]time.Time{"START": time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}, cfg.Dates)
+	assert.Equal(t, map[int]string{404: "not found"}, cfg.Codes)
+	assert.Equal(t, map[string]bool{"dark_mode": true, "beta": false}, cfg.Enabled)
+	assert.Equal(t, map[string]int{"httpClient": 3, "db": 5}, cfg.Retries)
+	assert.Empty(t, cfg.Unmatched)
+}
+
+func TestLoad_TypedEnvMaps_Errors(t *testing.T) {
+	type config struct {
+		Limits map[string]int `env:"prefix=LIMIT_"`
+	}
+	err := Load(&config{}, MapEnvReader{
+		"LIMIT_CPU": "four",
+	})
+	require.Error(t, err)
+	assert.Equal(t, "env var 'LIMIT_CPU' is not an int", err.Error())
+	assert.Equal(t, "Limits", err.(*LoadErrors).Errors[0].(*ParseError).Field)
+
+	type badKey struct {
+		Codes map[int]string `env:"prefix=CODE_"`
+	}
+	err = Load(&badKey{}, MapEnvReader{
+		"CODE_X": "x",
+	})
+	require.Error(t, err)
+	assert.Equal(t, "env var 'CODE_X' is not an int", err.Error())
+
+	type badDate struct {
+		Dates map[string]time.Time `env:"prefix=DATE_"`
+	}
+	err = Load(&badDate{}, NewDatetimeSetter("2006-01-02"), MapEnvReader{
+		"DATE_START": "x",
+	})
+	require.Error(t, err)
+	assert.Equal(t, "env var 'DATE_START' is invalid: parsing time \"x\" as \"2006-01-02\": cannot parse \"x\" as \"2006\"", err.Error())
+}
+
+func TestLoad_TypedEnvMaps_TagErrors(t *testing.T) {
+	testCases := []struct {
+		cfg         any
+		expectError string
+	}{
+		{
+			cfg: &struct {
+				Test map[string]int `env:"prefix=TEST_,keycase=title"`
+			}{},
+			expectError: "env tag 'keycase' on field 'Test' - invalid case 'title' (must be lower, upper or camel)",
+		},
+		{
+			cfg: &struct {
+				Test map[string]int `env:"keycase=lower"`
+			}{},
+			expectError: "cannot use env tags 'keycase' or 'keytrim' on field 'Test' (only for maps with 'prefix' or 'match')",
+		},
+		{
+			cfg: &struct {
+				Test string `env:"keytrim=_X"`
+			}{},
+			expectError: "cannot use env tags 'keycase' or 'keytrim' on field 'Test' (only for maps with 'prefix' or 'match')",
+		},
+		{
+			cfg: &struct {
+				Test map[string][]string
+			}{},
+			expectError: "field 'Test' has nested collection type (use env tag 'delims' with a delimiter for each of the 2 levels)",
+		},
+		{
+			cfg: &struct {
+				Test map[string]testDbConfig `env:"prefix=TEST,match=x"`
+			}{},
+			expectError: "cannot use env tag 'match' on field 'Test' (only for maps - other than maps of structs)",
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("[%d]", i+1), func(t *testing.T) {
+			err := Load(tc.cfg, MapEnvReader{})
+			require.Error(t, err)
+			assert.Equal(t, tc.expectError, err.Error())
+		})
+	}
+}
+
+func TestLoad_PointerCollectionItems(t *testing.T) {
+	type config struct {
+		Ints []*int
+		Map  map[string]*float64
+	}
+	cfg := &config{}
+	err := Load(cfg, MapEnvReader{
+		"INTS": "1,2",
+		"MAP":  "a:1.5",
+	})
+	require.NoError(t, err)
+	require.Len(t, cfg.Ints, 2)
+	assert.Equal(t, 2, *cfg.Ints[1])
+	assert.Equal(t, 1.5, *cfg.Map["a"])
+}
+
+func TestWrite_TypedEnvMaps(t *testing.T) {
+	type config struct {
+		Hosts map[string][]string `env:"prefix=HOSTS_"`
+		Ptrs  map[string]*int     `env:"prefix=PTR_"`
+	}
+	one := 1
+	cfg := &config{
+		Hosts: map[string][]string{"EU": {"a", "b"}},
+		Ptrs:  map[string]*int{"X": &one, "Y": nil},
+	}
+	var w bytes.Buffer
+	err := Write(&w, cfg)
+	require.NoError(t, err)
+	assert.Contains(t, w.String(), "HOSTS_EU=a,b\n")
+	assert.Contains(t, w.String(), "PTR_X=1\n")
+	assert.NotContains(t, w.String(), "PTR_Y")
+}
+
+func TestWrite_TypedEnvMaps_RoundTrip(t *testing.T) {
+	type sub struct {
+		Limits map[string]int `env:"prefix=LIMIT_"`
+	}
+	type config struct {
+		Sub      sub               `env:"prefix=SUB"`
+		Codes    map[string]string `env:"match='^CODE_[0-9]+$'"`
+		Retries  map[string]int    `env:"prefix=RETRY_,match='^[A-Z]+$'"`
+		Enabled  map[string]bool   `env:"match='^FEATURE_(?P<key>.+)_ENABLED$'"`
+		Cased    map[string]int    `env:"prefix=CASED_,keycase=lower"`
+		Trimmed  map[string]int    `env:"prefix=TRIMMED_,keytrim=_COUNT"`
+		Features map[string]bool   `env:"prefix=FEATURES_"`
+	}
+	cfg := &config{
+		Sub:      sub{Limits: map[string]int{"FREE": 10, "PRO": 100}},
+		Codes:    map[string]string{"CODE_1": "a"},
+		Retries:  map[string]int{"DB": 3},
+		Features: map[string]bool{},
+	}
+	var w bytes.Buffer
+	err := Write(&w, cfg, NewPrefix("APP"))
+	require.NoError(t, err)
+	// empty maps (including those whose keys are transformed) write nothing...
+	assert.Equal(t, `APP_SUB_LIMIT_FREE=10
+APP_SUB_LIMIT_PRO=100
+APP_RETRY_DB=3
+CODE_1=a
+`, w.String())
+
+	loaded := &config{}
+	err = Load(loaded, NewPrefix("APP"), NewEnvFileReader(&w, nil))
+	require.NoError(t, err)
+	assert.Equal(t, cfg.Sub, loaded.Sub)
+	assert.Equal(t, cfg.Codes, loaded.Codes)
+	assert.Equal(t, cfg.Retries, loaded.Retries)
+
+	w.Reset()
+	err = Example(&w, &config{})
+	require.NoError(t, err)
+	assert.Empty(t, w.String())
+}
+
+func TestWrite_TypedEnvMaps_TransformedKeys(t *testing.T) {
+	testCases := []struct {
+		cfg    any
+		expect string
+	}{
+		{
+			cfg: &struct {
+				Enabled map[string]bool `env:"match='^FEATURE_(?P<key>.+)_ENABLED$'"`
+			}{Enabled: map[string]bool{"NEW_UI": true}},
+			expect: "cannot write map field 'Enabled' - env var names cannot be determined from transformed keys (env tags 'keytrim', 'keycase' or a 'match' capture group)",
+		},
+		{
+			cfg: &struct {
+				Cased map[string]int `env:"prefix=CASED_,keycase=lower"`
+			}{Cased: map[string]int{"free": 10}},
+			expect: "cannot write map field 'Cased' - env var names cannot be determined from transformed keys (env tags 'keytrim', 'keycase' or a 'match' capture group)",
+		},
+		{
+			cfg: &struct {
+				Trimmed map[string]int `env:"prefix=TRIMMED_,keytrim=_COUNT"`
+			}{Trimmed: map[string]int{"DB": 1}},
+			expect: "cannot write map field 'Trimmed' - env var names cannot be determined from transformed keys (env tags 'keytrim', 'keycase' or a 'match' capture group)",
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("[%d]", i+1), func(t *testing.T) {
+			var w bytes.Buffer
+			err := Write(&w, tc.cfg)
+			require.Error(t, err)
+			assert.Equal(t, tc.expect, err.Error())
+			var ute *UnsupportedTypeError
+			assert.True(t, errors.As(err, &ute))
+			assert.Empty(t, w.String())
+		})
+	}
+}
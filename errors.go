@@ -0,0 +1,387 @@
+package cfgenv
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// LoadErrors is the error returned by Load or LoadAs when one or more fields could not be loaded
+//
+// Unless a FailFastOption is passed, loading continues through the whole config struct (including nested
+// and embedded structs) and every problem encountered is collected into LoadErrors
+//
+// LoadErrors supports errors.Is and errors.As - which are checked against each of the collected errors
+type LoadErrors struct {
+	Errors []error
+}
+
+var _ error = (*LoadErrors)(nil)
+
+func (e *LoadErrors) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the collected errors
+func (e *LoadErrors) Unwrap() []error {
+	return e.Errors
+}
+
+// Is reports whether any of the collected errors matches target
+func (e *LoadErrors) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first of the collected errors that matches target and, if one is found, sets target to that error value
+func (e *LoadErrors) As(target any) bool {
+	for _, err := range e.Errors {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *LoadErrors) add(err error) {
+	if le, ok := err.(*LoadErrors); ok {
+		e.Errors = append(e.Errors, le.Errors...)
+	} else if err != nil {
+		e.Errors = append(e.Errors, err)
+	}
+}
+
+func (e *LoadErrors) errorOrNil() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e
+}
+
+// MissingVarError is the error used when a required env var is missing
+type MissingVarError struct {
+	// Name is the env var name
+	Name string
+	// Field is the path of the config struct field, e.g. "Database.Port"
+	Field string
+	// Type is the type of the config struct field
+	Type reflect.Type
+	// Err is the underlying cause (if any)
+	Err error
+}
+
+func (e *MissingVarError) Error() string {
+	return fmt.Sprintf("missing env var '%s'", e.Name)
+}
+
+func (e *MissingVarError) Unwrap() error {
+	return e.Err
+}
+
+// ParseError is the error used when an env var value cannot be parsed into the field type
+type ParseError struct {
+	// Name is the env var name
+	Name string
+	// Field is the path of the config struct field, e.g. "Database.Port"
+	Field string
+	// Type is the type being parsed into (e.g. the item type for slices)
+	Type reflect.Type
+	// Err is the underlying cause
+	Err error
+	msg string
+}
+
+func (e *ParseError) Error() string {
+	if e.msg != "" {
+		return fmt.Sprintf("env var '%s' %s", e.Name, e.msg)
+	} else if e.Err != nil {
+		return fmt.Sprintf("env var '%s' is invalid: %s", e.Name, e.Err.Error())
+	}
+	return fmt.Sprintf("env var '%s' is invalid", e.Name)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeError is the error used when an env var value cannot be decoded (see Decoder)
+type DecodeError struct {
+	// Name is the env var name
+	Name string
+	// Field is the path of the config struct field, e.g. "Database.Port"
+	Field string
+	// Type is the type of the config struct field
+	Type reflect.Type
+	// Encoding is the encoding of the Decoder used
+	Encoding string
+	// Err is the underlying cause
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	cause := ""
+	if e.Err != nil {
+		cause = e.Err.Error()
+	}
+	return fmt.Sprintf("unable to decode env var '%s' (encoding: '%s'): %s", e.Name, e.Encoding, cause)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// TagError is the error used when the env tag on a config struct field is invalid
+type TagError struct {
+	// Name is the env var name (empty where the name could not be determined)
+	Name string
+	// Field is the path of the config struct field, e.g. "Database.Port"
+	Field string
+	// Type is the type of the config struct field
+	Type reflect.Type
+	// Tag is the offending tag (or tag token)
+	Tag string
+	// Err is the underlying cause (if any)
+	Err error
+	msg string
+}
+
+func (e *TagError) Error() string {
+	if e.msg != "" {
+		return e.msg
+	}
+	return fmt.Sprintf("invalid tag '%s' on field '%s'", e.Tag, e.Field)
+}
+
+func (e *TagError) Unwrap() error {
+	return e.Err
+}
+
+// UnsupportedTypeError is the error used when a config struct field type is not supported
+type UnsupportedTypeError struct {
+	// Name is the env var name (empty where the name could not be determined)
+	Name string
+	// Field is the path of the config struct field, e.g. "Database.Port"
+	Field string
+	// Type is the type of the config struct field
+	Type reflect.Type
+	// Err is the underlying cause (if any)
+	Err error
+	msg string
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	if e.msg != "" {
+		return e.msg
+	}
+	return fmt.Sprintf("field '%s' has unsupported type - %s", e.Field, e.Type.String())
+}
+
+func (e *UnsupportedTypeError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError is the error used when a loaded value fails a validation tag (e.g. `env:"min=1"`)
+type ValidationError struct {
+	// Name is the env var name
+	Name string
+	// Field is the path of the config struct field, e.g. "Database.Port"
+	Field string
+	// Type is the type of the config struct field
+	Type reflect.Type
+	// Rule is the validation rule that failed, e.g. "min=1"
+	Rule string
+	// Err is the underlying cause (if any)
+	Err error
+	msg string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("env var '%s' failed validation '%s' - %s", e.Name, e.Rule, e.msg)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// AliasConflictError is the error used when both an env var and one of its deprecated names (see env tag 'alias')
+// are set with different values (and a StrictAliasesOption is passed)
+type AliasConflictError struct {
+	// Name is the env var name
+	Name string
+	// Alias is the deprecated env var name
+	Alias string
+	// Field is the path of the config struct field, e.g. "Database.Host"
+	Field string
+	// Type is the type of the config struct field
+	Type reflect.Type
+}
+
+func (e *AliasConflictError) Error() string {
+	return fmt.Sprintf("env var '%s' and deprecated env var '%s' are both set with different values", e.Name, e.Alias)
+}
+
+// FileError is the error used when the file named by an env var (see env tag 'file' and FileVarsOption) cannot be read
+type FileError struct {
+	// Name is the env var name
+	Name string
+	// Path is the path of the file (the env var value)
+	Path string
+	// Field is the path of the config struct field, e.g. "Database.Password"
+	Field string
+	// Type is the type of the config struct field
+	Type reflect.Type
+	// Err is the underlying cause
+	Err error
+}
+
+func (e *FileError) Error() string {
+	cause := ""
+	if e.Err != nil {
+		cause = e.Err.Error()
+	}
+	return fmt.Sprintf("unable to read file '%s' from env var '%s': %s", e.Path, e.Name, cause)
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// UnsetError is the error used when an env var cannot be unset after loading (see env tag 'unset' and
+// UnsetSecretsOption)
+//
+// The config has been loaded (LoadAs returns the config as well as the error)
+type UnsetError struct {
+	// Name is the env var name
+	Name string
+	// Err is the underlying cause
+	Err error
+}
+
+func (e *UnsetError) Error() string {
+	cause := ""
+	if e.Err != nil {
+		cause = e.Err.Error()
+	}
+	return fmt.Sprintf("unable to unset env var '%s': %s", e.Name, cause)
+}
+
+func (e *UnsetError) Unwrap() error {
+	return e.Err
+}
+
+// ConstraintError is the error used when a cross-field constraint (e.g. `env:"required_if='TLS_ENABLED=true'"` or
+// `env:"group=auth,exclusive"`) is not met
+type ConstraintError struct {
+	// Names is the env var names involved
+	Names []string
+	// Fields is the paths of the config struct fields involved
+	Fields []string
+	// Rule is the constraint that was not met, e.g. "group=auth,exclusive"
+	Rule string
+	// Err is the underlying cause (if any)
+	Err error
+	msg string
+}
+
+func (e *ConstraintError) Error() string {
+	return e.msg
+}
+
+func (e *ConstraintError) Unwrap() error {
+	return e.Err
+}
+
+// StructValidationError is the error used when a config struct that implements Validator fails validation
+type StructValidationError struct {
+	// Prefix is the env var prefix of the struct
+	Prefix string
+	// Field is the path of the config struct field (empty for the top-level config struct), e.g. "Database"
+	Field string
+	// Type is the type of the struct
+	Type reflect.Type
+	// Err is the error returned by Validate
+	Err error
+}
+
+func (e *StructValidationError) Error() string {
+	what := e.Field
+	if what == "" {
+		what = e.Type.String()
+	}
+	cause := ""
+	if e.Err != nil {
+		cause = e.Err.Error()
+	}
+	if e.Prefix != "" {
+		return fmt.Sprintf("'%s' (prefix '%s') failed validation - %s", what, e.Prefix, cause)
+	}
+	return fmt.Sprintf("'%s' failed validation - %s", what, cause)
+}
+
+func (e *StructValidationError) Unwrap() error {
+	return e.Err
+}
+
+func newTagError(fld reflect.StructField, tag string, cause error, format string, args ...any) *TagError {
+	return &TagError{
+		Type: fld.Type,
+		Tag:  tag,
+		Err:  cause,
+		msg:  fmt.Sprintf(format, args...),
+	}
+}
+
+func newUnsupportedTypeError(fld reflect.StructField, format string, args ...any) *UnsupportedTypeError {
+	return &UnsupportedTypeError{
+		Type: fld.Type,
+		msg:  fmt.Sprintf(format, args...),
+	}
+}
+
+// withFieldPath sets the field path and env var name on typed errors (where not already set)
+func withFieldPath(err error, path string, name string) error {
+	switch et := err.(type) {
+	case *MissingVarError:
+		et.Field = firstNonEmpty(et.Field, path)
+	case *ParseError:
+		et.Field = firstNonEmpty(et.Field, path)
+	case *DecodeError:
+		et.Field = firstNonEmpty(et.Field, path)
+	case *ValidationError:
+		et.Field = firstNonEmpty(et.Field, path)
+	case *AliasConflictError:
+		et.Field = firstNonEmpty(et.Field, path)
+	case *FileError:
+		et.Field = firstNonEmpty(et.Field, path)
+	case *TagError:
+		et.Field = firstNonEmpty(et.Field, path)
+		et.Name = firstNonEmpty(et.Name, name)
+	case *UnsupportedTypeError:
+		et.Field = firstNonEmpty(et.Field, path)
+		et.Name = firstNonEmpty(et.Name, name)
+	}
+	return err
+}
+
+func firstNonEmpty(s string, other string) string {
+	if s != "" {
+		return s
+	}
+	return other
+}
+
+func joinPath(path string, name string) string {
+	if path != "" {
+		return path + "." + name
+	}
+	return name
+}
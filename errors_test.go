@@ -0,0 +1,127 @@
+package cfgenv
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestLoadErrors(t *testing.T) {
+	err1 := errors.New("first")
+	err2 := fmt.Errorf("second: %w", errTest)
+	le := &LoadErrors{}
+	assert.Nil(t, le.errorOrNil())
+	le.add(err1)
+	le.add(nil)
+	le.add(&LoadErrors{Errors: []error{err2}})
+	assert.Len(t, le.Errors, 2)
+	assert.Equal(t, le, le.errorOrNil())
+	assert.Equal(t, "first\nsecond: test error", le.Error())
+	assert.Equal(t, []error{err1, err2}, le.Unwrap())
+
+	var err error = le
+	assert.True(t, errors.Is(err, err1))
+	assert.True(t, errors.Is(err, errTest))
+	assert.False(t, errors.Is(err, errors.New("other")))
+	var te *testError
+	assert.True(t, errors.As(err, &te))
+	assert.Equal(t, errTest, te)
+	le = &LoadErrors{Errors: []error{err1}}
+	assert.False(t, errors.As(le, &te))
+}
+
+type testError struct{}
+
+func (e *testError) Error() string {
+	return "test error"
+}
+
+var errTest = &testError{}
+
+func TestLoad_TypedErrors(t *testing.T) {
+	type dbConfig struct {
+		Host string
+		Port int
+		Key  string `env:"encoding=base64"`
+	}
+	type config struct {
+		Database dbConfig `env:"prefix=DB"`
+		Bad      string   `env:"unknown=foo"`
+		Unsup    error    `env:"name=UNSUPPORTED"`
+	}
+	err := Load(&config{}, MapEnvReader{"DB_PORT": "x", "DB_KEY": "not base64!"})
+	require.Error(t, err)
+
+	var mve *MissingVarError
+	require.True(t, errors.As(err, &mve))
+	assert.Equal(t, "DB_HOST", mve.Name)
+	assert.Equal(t, "Database.Host", mve.Field)
+	assert.Equal(t, reflect.TypeOf(""), mve.Type)
+	assert.Nil(t, mve.Unwrap())
+
+	var pe *ParseError
+	require.True(t, errors.As(err, &pe))
+	assert.Equal(t, "DB_PORT", pe.Name)
+	assert.Equal(t, "Database.Port", pe.Field)
+	assert.Equal(t, reflect.TypeOf(0), pe.Type)
+	assert.Equal(t, "env var 'DB_PORT' is not an int", pe.Error())
+	var ne *strconv.NumError
+	assert.True(t, errors.As(pe, &ne))
+
+	var de *DecodeError
+	require.True(t, errors.As(err, &de))
+	assert.Equal(t, "DB_KEY", de.Name)
+	assert.Equal(t, "Database.Key", de.Field)
+	assert.Equal(t, "base64", de.Encoding)
+	assert.NotNil(t, de.Unwrap())
+
+	var te *TagError
+	require.True(t, errors.As(err, &te))
+	assert.Equal(t, "Bad", te.Field)
+	assert.Equal(t, "BAD", te.Name)
+	assert.Equal(t, "unknown=foo", te.Tag)
+	assert.Equal(t, "invalid tag 'unknown=foo' on field 'Bad'", te.Error())
+
+	var ute *UnsupportedTypeError
+	require.True(t, errors.As(err, &ute))
+	assert.Equal(t, "Unsup", ute.Field)
+	assert.Equal(t, "UNSUPPORTED", ute.Name)
+	assert.Equal(t, "field 'Unsup' has unsupported type - error", ute.Error())
+
+	err = Load(&struct {
+		Database struct {
+			Bad string `env:"optional,unknown=foo"`
+		} `env:"prefix=DB"`
+	}{}, MapEnvReader{})
+	require.True(t, errors.As(err, &te))
+	assert.Equal(t, "Database.Bad", te.Field)
+	assert.Equal(t, "DB_BAD", te.Name)
+}
+
+func TestLoad_TypedErrors_FailFast(t *testing.T) {
+	type dbConfig struct {
+		Port int
+	}
+	type config struct {
+		Database dbConfig `env:"prefix=DB"`
+	}
+	err := Load(&config{}, MapEnvReader{"DB_PORT": "x"}, NewFailFast())
+	require.Error(t, err)
+	pe, ok := err.(*ParseError)
+	require.True(t, ok)
+	assert.Equal(t, "Database.Port", pe.Field)
+}
+
+func TestTypedErrors_Messages(t *testing.T) {
+	assert.Equal(t, "env var 'FOO' is invalid", (&ParseError{Name: "FOO"}).Error())
+	assert.Equal(t, "env var 'FOO' is invalid: test error", (&ParseError{Name: "FOO", Err: errTest}).Error())
+	assert.Equal(t, "unable to decode env var 'FOO' (encoding: 'base64'): ", (&DecodeError{Name: "FOO", Encoding: "base64"}).Error())
+	assert.Equal(t, "invalid tag 'foo' on field 'Foo'", (&TagError{Tag: "foo", Field: "Foo"}).Error())
+	assert.Equal(t, "field 'Foo' has unsupported type - error", (&UnsupportedTypeError{Field: "Foo", Type: reflect.TypeOf((*error)(nil)).Elem()}).Error())
+	assert.Nil(t, (&TagError{}).Unwrap())
+	assert.Nil(t, (&UnsupportedTypeError{}).Unwrap())
+}
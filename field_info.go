@@ -1,7 +1,6 @@
 package cfgenv
 
 import (
-	"fmt"
 	"github.com/go-andiamo/splitter"
 	"reflect"
 	"regexp"
@@ -9,23 +8,54 @@ import (
 )
 
 type fieldInfo struct {
-	name           string
-	optional       bool
-	pointer        bool
-	hasDefault     bool
-	defaultValue   string
-	prefix         string
-	isStruct       bool
-	isPrefixedMap  bool
-	isMatchedMap   bool
-	matchRegex     *regexp.Regexp
-	customSetter   CustomSetterOption
-	optionalSetter optionalSetterFn
-	decoder        Decoder
-	separator      string
-	delimiter      string
-	expand         bool
-	noExpand       bool
+	name               string
+	optional           bool
+	pointer            bool
+	hasDefault         bool
+	defaultValue       string
+	prefix             string
+	isStruct           bool
+	isStructSlice      bool
+	isStructMap        bool
+	isPrefixedMap      bool
+	isMatchedMap       bool
+	isJson             bool
+	matchRegex         *regexp.Regexp
+	matchKeyGroup      int
+	keyCase            string
+	keyTrim            string
+	customSetter       CustomSetterOption
+	itemSetter         CustomSetterOption
+	optionalSetter     optionalSetterFn
+	decoder            Decoder
+	separator          string
+	delimiter          string
+	delimiters         []string
+	depth              int
+	expand             bool
+	noExpand           bool
+	quoted             bool
+	extendedDurations  bool
+	unit               numericUnit
+	enums              map[reflect.Type]EnumOption
+	aliases            []string
+	file               bool
+	fileTrim           bool
+	secret             bool
+	unset              bool
+	format             CollectionFormat
+	hasFormat          bool
+	validations        []*validation
+	requiredIf         string
+	requiredIfName     string
+	requiredIfValue    string
+	requiredIfHasValue bool
+	group              string
+	groupMember        string
+	exclusive          bool
+	atLeastOne         bool
+	gapRule            IndexGapRule
+	hasGapRule         bool
 }
 
 var tagSplitter = splitter.MustCreateSplitter(',', splitter.DoubleQuotes, splitter.SingleQuotes).
@@ -34,18 +64,44 @@ var eqSplitter = splitter.MustCreateSplitter('=', splitter.DoubleQuotes, splitte
 	AddDefaultOptions(splitter.Trim(" "))
 
 const (
-	tokenDefault   = "default"
-	tokenDelim     = "delim"
-	tokenDelimiter = "delimiter"
-	tokenEncoding  = "encoding"
-	tokenExpand    = "expand"
-	tokenMatch     = "match"
-	tokenName      = "name"
-	tokenNoExpand  = "no-expand"
-	tokenOptional  = "optional"
-	tokenPrefix    = "prefix"
-	tokenSep       = "sep"
-	tokenSeparator = "separator"
+	tokenAlias      = "alias"
+	tokenAtLeastOne = "atleastone"
+	tokenDefault    = "default"
+	tokenDelim      = "delim"
+	tokenDelimiter  = "delimiter"
+	tokenDelims     = "delims"
+	tokenEncoding   = "encoding"
+	tokenExclusive  = "exclusive"
+	tokenExpand     = "expand"
+	tokenExtended   = "extended"
+	tokenFile       = "file"
+	tokenFormat     = "format"
+	tokenGaps       = "gaps"
+	tokenGroup      = "group"
+	tokenJson       = "json"
+	tokenKeyCase    = "keycase"
+	tokenKeyTrim    = "keytrim"
+	tokenMatch      = "match"
+	tokenMax        = "max"
+	tokenMaxLen     = "maxlen"
+	tokenMin        = "min"
+	tokenMinLen     = "minlen"
+	tokenName       = "name"
+	tokenNoExpand   = "no-expand"
+	tokenNoQuoted   = "no-quoted"
+	tokenNotEmpty   = "notempty"
+	tokenOneOf      = "oneof"
+	tokenOptional   = "optional"
+	tokenPattern    = "pattern"
+	tokenPrefix     = "prefix"
+	tokenQuoted     = "quoted"
+	tokenRequired   = "required"
+	tokenRequiredIf = "required_if"
+	tokenSecret     = "secret"
+	tokenSep        = "sep"
+	tokenSeparator  = "separator"
+	tokenUnit       = "unit"
+	tokenUnset      = "unset"
 )
 
 func getFieldInfo(fld reflect.StructField, options *opts) (*fieldInfo, error) {
@@ -53,10 +109,16 @@ func getFieldInfo(fld reflect.StructField, options *opts) (*fieldInfo, error) {
 	if err != nil {
 		return nil, err
 	}
+	result.quoted = options.quoted
+	result.extendedDurations = options.extendedDurations
+	if options.optionalByDefault {
+		result.optional = true
+	}
+	required := false
 	if tag, ok := fld.Tag.Lookup("env"); ok {
 		parts, err := tagSplitter.Split(tag)
 		if err != nil {
-			return nil, fmt.Errorf("invalid tag '%s' on field '%s'", tag, fld.Name)
+			return nil, newTagError(fld, tag, err, "invalid tag '%s' on field '%s'", tag, fld.Name)
 		}
 		for _, s := range parts {
 			if pts, _ := eqSplitter.Split(s); len(pts) == 2 {
@@ -64,69 +126,248 @@ func getFieldInfo(fld reflect.StructField, options *opts) (*fieldInfo, error) {
 				case tokenName:
 					result.name = unquoted(pts[1])
 					continue
+				case tokenAlias:
+					for _, alias := range strings.Split(unquoted(pts[1]), "|") {
+						if alias = strings.TrimSpace(alias); alias != "" {
+							result.aliases = append(result.aliases, alias)
+						}
+					}
+					continue
 				case tokenDefault:
 					result.hasDefault = true
 					result.defaultValue = unquoted(pts[1])
 					continue
 				case tokenPrefix:
 					result.prefix = unquoted(pts[1])
-					if fld.Type.Kind() == reflect.Map {
-						result.isPrefixedMap = fld.Type.Elem().Kind() == reflect.String && fld.Type.Key().Kind() == reflect.String
-					}
-					if !result.isPrefixedMap && !result.isStruct {
-						return nil, fmt.Errorf("cannot use env tag 'prefix' on field '%s' (only for structs or map[string]string)", fld.Name)
+					result.isPrefixedMap = fld.Type.Kind() == reflect.Map && !result.isStructMap && result.customSetter == nil
+					if !result.isPrefixedMap && !result.isStruct && !result.isStructSlice && !result.isStructMap {
+						return nil, newTagError(fld, s, nil, "cannot use env tag 'prefix' on field '%s' (only for structs, slices of structs or maps)", fld.Name)
 					}
 					continue
 				case tokenMatch:
-					if fld.Type.Kind() == reflect.Map {
-						result.isMatchedMap = fld.Type.Elem().Kind() == reflect.String && fld.Type.Key().Kind() == reflect.String
-					}
-					if !result.isMatchedMap {
-						return nil, fmt.Errorf("cannot use env tag 'match' on field '%s' (only for map[string]string)", fld.Name)
+					if result.isMatchedMap = fld.Type.Kind() == reflect.Map && !result.isStructMap && result.customSetter == nil; !result.isMatchedMap {
+						return nil, newTagError(fld, s, nil, "cannot use env tag 'match' on field '%s' (only for maps - other than maps of structs)", fld.Name)
 					}
 					rxs := unquoted(pts[1])
 					if result.matchRegex, err = regexp.Compile(rxs); err != nil {
-						return nil, fmt.Errorf("env tag 'match' on field '%s' - invalid regexp: %s", fld.Name, err.Error())
+						return nil, newTagError(fld, s, err, "env tag 'match' on field '%s' - invalid regexp: %s", fld.Name, err.Error())
+					}
+					result.matchKeyGroup = matchKeyGroup(result.matchRegex)
+					continue
+				case tokenKeyCase:
+					if result.keyCase = unquoted(pts[1]); !keyCases[result.keyCase] {
+						return nil, newTagError(fld, s, nil, "env tag '%s' on field '%s' - invalid case '%s' (must be lower, upper or camel)", tokenKeyCase, fld.Name, pts[1])
 					}
 					continue
+				case tokenKeyTrim:
+					result.keyTrim = unquoted(pts[1])
+					continue
 				case tokenSeparator, tokenSep:
 					result.separator = unquoted(pts[1])
 					continue
 				case tokenDelimiter, tokenDelim:
 					result.delimiter = unquoted(pts[1])
 					continue
+				case tokenDelims:
+					result.delimiters = make([]string, 0)
+					for _, r := range unquoted(pts[1]) {
+						result.delimiters = append(result.delimiters, string(r))
+					}
+					continue
 				case tokenEncoding:
 					if dec, ok := options.decoders[unquoted(pts[1])]; ok {
 						result.decoder = dec
 						continue
 					} else {
-						return nil, fmt.Errorf("unknown encoding '%s' on field '%s'", pts[1], fld.Name)
+						return nil, newTagError(fld, s, nil, "unknown encoding '%s' on field '%s'", pts[1], fld.Name)
+					}
+				case tokenRequiredIf:
+					result.requiredIf = unquoted(pts[1])
+					cond := strings.SplitN(result.requiredIf, "=", 2)
+					if result.requiredIfName = strings.TrimSpace(cond[0]); result.requiredIfName == "" {
+						return nil, newTagError(fld, s, nil, "env tag '%s' on field '%s' - missing env var name", tokenRequiredIf, fld.Name)
+					}
+					if result.requiredIfHasValue = len(cond) == 2; result.requiredIfHasValue {
+						result.requiredIfValue = cond[1]
+					}
+					result.optional = true
+					continue
+				case tokenGroup:
+					grp := strings.SplitN(unquoted(pts[1]), ":", 2)
+					if result.group = grp[0]; result.group == "" {
+						return nil, newTagError(fld, s, nil, "env tag '%s' on field '%s' - missing group name", tokenGroup, fld.Name)
+					}
+					if len(grp) == 2 {
+						result.groupMember = grp[1]
+					}
+					result.optional = true
+					continue
+				case tokenUnit:
+					var ok bool
+					if result.unit, ok = numericUnitNames[unquoted(pts[1])]; !ok {
+						return nil, newTagError(fld, s, nil, "env tag '%s' on field '%s' - invalid unit '%s' (must be bytes, si or percent)", tokenUnit, fld.Name, pts[1])
+					} else if !result.unit.supports(fld.Type) || result.customSetter != nil || result.isJson {
+						return nil, newTagError(fld, s, nil, "cannot use env tag '%s=%s' on field '%s' (%s)", tokenUnit, unquoted(pts[1]), fld.Name, result.unit.supportedDesc())
+					}
+					continue
+				case tokenFile:
+					if unquoted(pts[1]) != fileTrim {
+						return nil, newTagError(fld, s, nil, "env tag '%s' on field '%s' - invalid value '%s' (must be %s)", tokenFile, fld.Name, pts[1], fileTrim)
+					}
+					result.file = true
+					result.fileTrim = true
+					continue
+				case tokenGaps:
+					if !isIndexableSlice(fld.Type) {
+						return nil, newTagError(fld, s, nil, "cannot use env tag '%s' on field '%s' (only for slices)", tokenGaps, fld.Name)
+					} else if result.gapRule, result.hasGapRule = indexGapRuleNames[unquoted(pts[1])]; !result.hasGapRule {
+						return nil, newTagError(fld, s, nil, "env tag '%s' on field '%s' - invalid rule '%s' (must be compact, stop or error)", tokenGaps, fld.Name, pts[1])
 					}
+					continue
+				case tokenFormat:
+					if result.format, result.hasFormat = collectionFormatNames[unquoted(pts[1])]; !result.hasFormat {
+						return nil, newTagError(fld, s, nil, "env tag '%s' on field '%s' - invalid format '%s' (must be delimited, json, query, lines or csv)", tokenFormat, fld.Name, pts[1])
+					}
+					continue
+				case tokenMin, tokenMax, tokenMinLen, tokenMaxLen, tokenOneOf, tokenPattern:
+					vld, err := newValidation(fld, pts[0], unquoted(pts[1]))
+					if err != nil {
+						return nil, err
+					}
+					result.validations = append(result.validations, vld)
+					continue
 				}
-				return nil, fmt.Errorf("invalid tag '%s' on field '%s'", s, fld.Name)
+				return nil, newTagError(fld, s, nil, "invalid tag '%s' on field '%s'", s, fld.Name)
 			} else if len(pts) == 1 {
 				switch s {
 				case tokenOptional:
 					result.optional = true
+				case tokenRequired:
+					required = true
 				case tokenExpand:
 					result.expand = true
 					result.noExpand = false
 				case tokenNoExpand:
 					result.noExpand = true
 					result.expand = false
-				case tokenDefault, tokenPrefix, tokenSeparator, tokenSep, tokenDelimiter, tokenDelim, tokenMatch, tokenEncoding:
-					return nil, fmt.Errorf("cannot use env tag '%s' without value on field '%s' (use quotes if necessary)", s, fld.Name)
+				case tokenExtended:
+					result.extendedDurations = true
+				case tokenFile:
+					result.file = true
+				case tokenSecret:
+					result.secret = true
+				case tokenUnset:
+					result.unset = true
+				case tokenJson:
+					// already determined by checkFieldType
+				case tokenQuoted:
+					result.quoted = true
+				case tokenNoQuoted:
+					result.quoted = false
+				case tokenExclusive:
+					result.exclusive = true
+				case tokenAtLeastOne:
+					result.atLeastOne = true
+				case tokenNotEmpty:
+					vld, _ := newValidation(fld, tokenNotEmpty, "")
+					result.validations = append(result.validations, vld)
+				case tokenAlias, tokenDefault, tokenPrefix, tokenSeparator, tokenSep, tokenDelimiter, tokenDelim, tokenDelims, tokenMatch, tokenEncoding, tokenFormat, tokenGaps, tokenKeyCase, tokenKeyTrim, tokenUnit,
+					tokenMin, tokenMax, tokenMinLen, tokenMaxLen, tokenOneOf, tokenPattern, tokenRequiredIf, tokenGroup:
+					return nil, newTagError(fld, s, nil, "cannot use env tag '%s' without value on field '%s' (use quotes if necessary)", s, fld.Name)
 				default:
 					result.name = unquoted(s)
 				}
 			} else {
-				return nil, fmt.Errorf("invalid tag '%s' on field '%s'", s, fld.Name)
+				return nil, newTagError(fld, s, nil, "invalid tag '%s' on field '%s'", s, fld.Name)
 			}
 		}
+		if (result.keyCase != "" || result.keyTrim != "") && !result.isPrefixedMap && !result.isMatchedMap {
+			return nil, newTagError(fld, tag, nil, "cannot use env tags '%s' or '%s' on field '%s' (only for maps with 'prefix' or 'match')", tokenKeyCase, tokenKeyTrim, fld.Name)
+		}
+		if len(result.validations) > 0 && result.isStruct {
+			return nil, newTagError(fld, tag, nil, "cannot use validation env tags on struct field '%s'", fld.Name)
+		}
+		if result.hasFormat && (result.customSetter != nil || result.isJson || !result.format.supports(result.formatType(fld))) {
+			return nil, newTagError(fld, tag, nil, "cannot use env tag '%s=%s' on field '%s' (unsupported collection type)", tokenFormat, formatName(result.format), fld.Name)
+		}
+		if (len(result.aliases) > 0 || result.file || result.unset) && (result.isStruct || result.isStructSlice || result.isStructMap || result.isPrefixedMap || result.isMatchedMap) {
+			return nil, newTagError(fld, tag, nil, "cannot use env tags '%s', '%s' or '%s' on field '%s' (only for fields loaded from a single env var)", tokenAlias, tokenFile, tokenUnset, fld.Name)
+		}
+		if required && (hasTagToken(fld, tokenOptional) || result.hasDefault || result.requiredIf != "" || result.group != "") {
+			return nil, newTagError(fld, tag, nil, "cannot use env tag '%s' with '%s', '%s', '%s' or '%s' on field '%s'", tokenRequired, tokenOptional, tokenDefault, tokenRequiredIf, tokenGroup, fld.Name)
+		} else if required {
+			result.optional = false
+		}
+		if (result.exclusive || result.atLeastOne) && result.group == "" {
+			return nil, newTagError(fld, tag, nil, "cannot use env tags '%s' or '%s' without '%s' on field '%s'", tokenExclusive, tokenAtLeastOne, tokenGroup, fld.Name)
+		} else if (result.group != "" || result.requiredIf != "") && (result.isStruct || result.isStructSlice || result.isStructMap) {
+			return nil, newTagError(fld, tag, nil, "cannot use env tags '%s' or '%s' on struct field '%s'", tokenGroup, tokenRequiredIf, fld.Name)
+		} else if result.secret && (result.isStruct || result.isStructSlice || result.isStructMap) {
+			return nil, newTagError(fld, tag, nil, "cannot use env tag '%s' on struct field '%s'", tokenSecret, fld.Name)
+		}
+	}
+	if result.extendedDurations && fld.Type == optDurationType && result.customSetter == nil {
+		result.optionalSetter = optionalDurationSetter(true)
+	} else if result.unit != unitNone && isOptionalType(fld.Type) {
+		result.optionalSetter = reflectOptionalSetter(result.unit.setNumber)
+	}
+	if !result.hasFormat && result.customSetter == nil && !result.isJson && options.collectionFormat.supports(result.formatType(fld)) {
+		result.format = options.collectionFormat
+	}
+	if result.isStructMap && result.prefix == "" {
+		return nil, newUnsupportedTypeError(fld, "field '%s' has unsupported map item type (use env tag 'prefix' for maps of structs)", fld.Name)
+	} else if levels := result.delimitedLevels(); levels > 1 && len(result.delimiters) < levels && result.format == CollectionFormatDelimited {
+		return nil, newUnsupportedTypeError(fld, "field '%s' has nested collection type (use env tag 'delims' with a delimiter for each of the %d levels)", fld.Name, levels)
 	}
 	return result, nil
 }
 
+// isIgnoredField determines whether the field has the env tag "-" (and is neither loaded nor written)
+func isIgnoredField(fld reflect.StructField) bool {
+	tag, ok := fld.Tag.Lookup("env")
+	return ok && tag == "-"
+}
+
+// hasTagToken determines whether the field's env tag has the (valueless) token
+func hasTagToken(fld reflect.StructField, token string) bool {
+	if tag, ok := fld.Tag.Lookup("env"); ok {
+		parts, _ := tagSplitter.Split(tag)
+		for _, s := range parts {
+			if s == token {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// tagTokens is the set of env tag tokens (other than 'name') - i.e. tag parts that are never taken as the env var name
+var tagTokens = map[string]bool{
+	tokenAlias: true, tokenAtLeastOne: true, tokenDefault: true, tokenDelim: true, tokenDelimiter: true, tokenDelims: true,
+	tokenEncoding: true, tokenExclusive: true, tokenExpand: true, tokenExtended: true, tokenFile: true, tokenFormat: true,
+	tokenGaps: true, tokenGroup: true, tokenJson: true, tokenKeyCase: true, tokenKeyTrim: true, tokenMatch: true,
+	tokenMax: true, tokenMaxLen: true, tokenMin: true, tokenMinLen: true, tokenNoExpand: true, tokenNoQuoted: true,
+	tokenNotEmpty: true, tokenOneOf: true, tokenOptional: true, tokenPattern: true, tokenPrefix: true, tokenQuoted: true,
+	tokenRequired: true, tokenRequiredIf: true, tokenSecret: true, tokenSep: true, tokenSeparator: true, tokenUnit: true,
+	tokenUnset: true,
+}
+
+// tagName returns the env var name override (if any) in the field's env tag - without fully parsing the tag, so that
+// the env var can still be named where the field info cannot be determined (e.g. for a TagError)
+func tagName(fld reflect.StructField) (name string) {
+	if tag, ok := fld.Tag.Lookup("env"); ok {
+		parts, _ := tagSplitter.Split(tag)
+		for _, s := range parts {
+			if pts, _ := eqSplitter.Split(s); len(pts) == 2 && pts[0] == tokenName {
+				name = unquoted(pts[1])
+			} else if len(pts) == 1 && !tagTokens[s] {
+				name = unquoted(s)
+			}
+		}
+	}
+	return
+}
+
 func unquoted(s string) string {
 	if (strings.HasPrefix(s, `"`) && strings.HasSuffix(s, `"`)) ||
 		(strings.HasPrefix(s, `'`) && strings.HasSuffix(s, `'`)) {
@@ -147,67 +388,117 @@ func checkFieldType(fld reflect.StructField, options *opts) (*fieldInfo, error)
 		separator: ":",
 		delimiter: ",",
 	}
+	if isNestedSecretType(fld.Type) {
+		return nil, newUnsupportedTypeError(fld, "field '%s' has unsupported secret type - %s (use Secret as the field type, e.g. Secret[*T] or Secret[[]T])", fld.Name, fld.Type.String())
+	}
+	if result.isJson = hasTagToken(fld, tokenJson); result.isJson {
+		// any type can be unmarshalled from JSON...
+		return result, nil
+	}
 	for _, c := range options.customs {
 		if ok := c.IsApplicable(fld); ok {
 			result.customSetter = c
 			return result, nil
 		}
 	}
-	if setFn, ok := optionalTypeSetters[fld.Type]; ok {
+	result.enums = options.enums
+	if isOptionalType(fld.Type) && options.enums[optionalItemType(fld.Type)] != nil {
+		result.optional = true
+		result.optionalSetter = reflectOptionalSetter(enumSetter(options.enums[optionalItemType(fld.Type)]))
+		return result, nil
+	} else if setFn, ok := optionalTypeSetters[fld.Type]; ok {
 		result.optional = true
 		result.optionalSetter = setFn
 		return result, nil
+	} else if isOptionalType(fld.Type) && isTextType(optionalItemType(fld.Type)) {
+		result.optional = true
+		result.optionalSetter = reflectOptionalSetter(unmarshalText)
+		return result, nil
 	}
-	if isNativeType(k) {
+	if isNativeType(k) || isTextType(fld.Type) {
 		return result, nil
 	}
 	switch k {
 	case reflect.Slice:
 		if isPtr {
-			return nil, fmt.Errorf("field '%s' has unsupported type - %s", fld.Name, fld.Type.String())
+			return nil, newUnsupportedTypeError(fld, "field '%s' has unsupported type - %s", fld.Name, fld.Type.String())
 		} else {
 			// check slice item type...
 			it := fld.Type.Elem()
 			if it.Kind() == reflect.Pointer {
 				it = it.Elem()
 			}
-			if !isNativeType(it.Kind()) {
-				return nil, fmt.Errorf("field '%s' has unsupported slice item type", fld.Name)
+			if it.Kind() == reflect.Struct && !isTextType(it) {
+				result.isStructSlice = true
+			} else if depth, ok := nestedCollectionDepth(fld.Type.Elem()); ok {
+				result.depth = depth + 1
+			} else {
+				return nil, newUnsupportedTypeError(fld, "field '%s' has unsupported slice item type", fld.Name)
 			}
 		}
 	case reflect.Map:
 		if isPtr {
-			return nil, fmt.Errorf("field '%s' has unsupported type - %s", fld.Name, fld.Type.String())
+			return nil, newUnsupportedTypeError(fld, "field '%s' has unsupported type - %s", fld.Name, fld.Type.String())
 		} else {
 			// check map item type...
 			it := fld.Type.Elem()
 			if it.Kind() == reflect.Pointer {
 				it = it.Elem()
 			}
-			if !isNativeType(it.Kind()) {
-				return nil, fmt.Errorf("field '%s' has unsupported map item type", fld.Name)
-			} else {
+			result.depth = 1
+			if result.itemSetter = itemCustomSetter(fld, fld.Type.Elem(), options); result.itemSetter == nil && !isSetItemType(it) {
+				if it.Kind() == reflect.Struct && !isTextType(it) && fld.Type.Key().Kind() == reflect.String {
+					result.isStructMap = true
+				} else if depth, ok := nestedCollectionDepth(fld.Type.Elem()); ok {
+					result.depth = depth + 1
+				} else {
+					return nil, newUnsupportedTypeError(fld, "field '%s' has unsupported map item type", fld.Name)
+				}
+			}
+			if !result.isStructMap {
 				// check map key type...
 				it = fld.Type.Key()
-				if !isNativeType(it.Kind()) {
-					return nil, fmt.Errorf("field '%s' has unsupported map key type", fld.Name)
+				if !isNativeType(it.Kind()) && !isTextType(it) {
+					return nil, newUnsupportedTypeError(fld, "field '%s' has unsupported map key type", fld.Name)
 				}
 			}
 		}
+	case reflect.Array:
+		if isPtr {
+			return nil, newUnsupportedTypeError(fld, "field '%s' has unsupported type - %s", fld.Name, fld.Type.String())
+		} else if it := fld.Type.Elem(); it.Kind() != reflect.Uint8 {
+			// check array item type...
+			if !isSupportedItemType(it) {
+				return nil, newUnsupportedTypeError(fld, "field '%s' has unsupported array item type", fld.Name)
+			}
+			result.depth = 1
+		}
 	case reflect.Struct:
 		result.isStruct = true
 	default:
-		return nil, fmt.Errorf("field '%s' has unsupported type - %s", fld.Name, fld.Type.String())
+		return nil, newUnsupportedTypeError(fld, "field '%s' has unsupported type - %s", fld.Name, fld.Type.String())
 	}
 	return result, nil
 }
 
+// itemCustomSetter returns the first custom setter (if any) applicable to the collection item type of the field
+func itemCustomSetter(fld reflect.StructField, it reflect.Type, options *opts) CustomSetterOption {
+	itemFld := fld
+	itemFld.Type = it
+	for _, c := range options.customs {
+		if c.IsApplicable(itemFld) {
+			return c
+		}
+	}
+	return nil
+}
+
 func isNativeType(k reflect.Kind) bool {
 	switch k {
 	case reflect.String, reflect.Bool,
 		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
 		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
-		reflect.Float32, reflect.Float64:
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
 		return true
 	}
 	return false
@@ -0,0 +1,80 @@
+package cfgenv
+
+import (
+	"os"
+	"reflect"
+	"strings"
+)
+
+// fileTrim is the value of the `file` env tag denoting file contents are trimmed, i.e. `env:"file=trim"`
+const fileTrim = "trim"
+
+// FileVarsOption is an option that can be passed to Load or LoadAs
+// and, following the Docker secrets convention, determines that when an env var is missing the env var with the
+// file suffix (e.g. "DB_PASSWORD_FILE") is checked - and, if set, the field is loaded from the contents of the file
+// at that path
+type FileVarsOption interface {
+	// FileSuffix returns the suffix of the env var names that hold file paths, e.g. "_FILE"
+	FileSuffix() string
+	// TrimFile returns whether leading and trailing whitespace (e.g. a trailing newline) is trimmed from file contents
+	TrimFile() bool
+}
+
+type fileVarsOpt struct {
+	suffix string
+	trim   bool
+}
+
+func (f *fileVarsOpt) FileSuffix() string {
+	return f.suffix
+}
+
+func (f *fileVarsOpt) TrimFile() bool {
+	return f.trim
+}
+
+// NewFileVars creates a new FileVarsOption with the file suffix "_FILE" - where trim determines whether leading and
+// trailing whitespace is trimmed from file contents
+func NewFileVars(trim bool) FileVarsOption {
+	return &fileVarsOpt{suffix: "_FILE", trim: trim}
+}
+
+// NewFileVarsSuffix creates a new FileVarsOption with the specified file suffix - where trim determines whether leading
+// and trailing whitespace is trimmed from file contents
+func NewFileVarsSuffix(suffix string, trim bool) FileVarsOption {
+	return &fileVarsOpt{suffix: suffix, trim: trim}
+}
+
+// lookupEnv looks up the env var value for a field (see lookupAliased) - where the field has the `file` env tag, the
+// env var value is the path of the file to read and, with a FileVarsOption, a missing env var falls back to the file
+// named by the file suffixed env var
+func (o *opts) lookupEnv(name string, prefix string, path string, fld reflect.StructField, fi *fieldInfo) (string, bool, error) {
+	raw, ok, err := o.lookupAliased(name, prefix, path, fld, fi)
+	if ok {
+		o.consume(name, prefix, fld, fi)
+	}
+	if err != nil || (ok && !fi.file) {
+		return raw, ok, err
+	} else if ok {
+		return readEnvFile(name, raw, fi.fileTrim, path, fld)
+	} else if o.fileVars != nil && !fi.file {
+		fileName := name + o.fileVars.FileSuffix()
+		if filePath, fileOk := o.reader.LookupEnv(fileName); fileOk {
+			o.consume(name, prefix, fld, fi)
+			return readEnvFile(fileName, filePath, o.fileVars.TrimFile(), path, fld)
+		}
+	}
+	return raw, ok, nil
+}
+
+// readEnvFile reads the contents of the file at the path held by an env var
+func readEnvFile(name string, filePath string, trim bool, path string, fld reflect.StructField) (string, bool, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", true, &FileError{Name: name, Path: filePath, Field: path, Type: fld.Type, Err: err}
+	}
+	if trim {
+		return strings.TrimSpace(string(data)), true, nil
+	}
+	return string(data), true, nil
+}
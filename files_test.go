@@ -0,0 +1,162 @@
+package cfgenv
+
+import (
+	"bytes"
+	"errors"
+	"github.com/go-andiamo/gopt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, name string, content string) string {
+	fn := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(fn, []byte(content), 0600))
+	return fn
+}
+
+func TestLoad_FileTag(t *testing.T) {
+	type config struct {
+		Password string             `env:"file"`
+		Token    string             `env:"file=trim"`
+		Port     int                `env:"file=trim"`
+		Hosts    []string           `env:"file=trim"`
+		Key      []byte             `env:"file=trim,encoding=base64"`
+		Greeting string             `env:"file=trim,expand"`
+		Settings map[string]any     `env:"file,json"`
+		Timeout  gopt.Optional[int] `env:"file=trim"`
+		Missing  string             `env:"file,optional"`
+	}
+	cfg := &config{}
+	err := Load(cfg, MapEnvReader{
+		"PASSWORD": writeTestFile(t, "password", "secret\n"),
+		"TOKEN":    writeTestFile(t, "token", "  abc\n"),
+		"PORT":     writeTestFile(t, "port", "8080\n"),
+		"HOSTS":    writeTestFile(t, "hosts", "a,b\n"),
+		"KEY":      writeTestFile(t, "key", "aGVsbG8=\n"),
+		"GREETING": writeTestFile(t, "greeting", "hello ${NAME}\n"),
+		"SETTINGS": writeTestFile(t, "settings", `{"foo":"bar"}`),
+		"TIMEOUT":  writeTestFile(t, "timeout", "30"),
+		"NAME":     "world",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "secret\n", cfg.Password)
+	assert.Equal(t, "abc", cfg.Token)
+	assert.Equal(t, 8080, cfg.Port)
+	assert.Equal(t, []string{"a", "b"}, cfg.Hosts)
+	assert.Equal(t, []byte("hello"), cfg.Key)
+	assert.Equal(t, "hello world", cfg.Greeting)
+	assert.Equal(t, map[string]any{"foo": "bar"}, cfg.Settings)
+	assert.Equal(t, 30, cfg.Timeout.Default(0))
+	assert.Equal(t, "", cfg.Missing)
+}
+
+func TestLoad_FileTag_Errors(t *testing.T) {
+	type config struct {
+		Password string `env:"file"`
+		Port     int    `env:"file=trim"`
+	}
+	missing := filepath.Join(t.TempDir(), "missing")
+	err := Load(&config{}, MapEnvReader{"PASSWORD": missing, "PORT": writeTestFile(t, "port", "x")})
+	require.Error(t, err)
+	var fe *FileError
+	require.True(t, errors.As(err, &fe))
+	assert.Equal(t, "PASSWORD", fe.Name)
+	assert.Equal(t, missing, fe.Path)
+	assert.Equal(t, "Password", fe.Field)
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+	assert.Contains(t, err.Error(), "unable to read file '"+missing+"' from env var 'PASSWORD': ")
+	assert.Contains(t, err.Error(), "env var 'PORT' is not an int")
+
+	err = Load(&config{}, MapEnvReader{})
+	require.Error(t, err)
+	assert.Equal(t, "missing env var 'PASSWORD'\nmissing env var 'PORT'", err.Error())
+
+	err = Load(&struct {
+		Test string `env:"file=foo"`
+	}{}, MapEnvReader{})
+	require.Error(t, err)
+	assert.Equal(t, "env tag 'file' on field 'Test' - invalid value 'foo' (must be trim)", err.Error())
+
+	err = Load(&struct {
+		Test map[string]string `env:"file,prefix=TEST_"`
+	}{}, MapEnvReader{})
+	require.Error(t, err)
+	assert.Equal(t, "cannot use env tags 'alias', 'file' or 'unset' on field 'Test' (only for fields loaded from a single env var)", err.Error())
+}
+
+func TestLoad_FileVars(t *testing.T) {
+	type sub struct {
+		Password string
+	}
+	type config struct {
+		Password string
+		User     string `env:"optional"`
+		Db       sub    `env:"prefix=DB"`
+		Port     int
+		Name     string `env:"file"`
+	}
+	env := MapEnvReader{
+		"PASSWORD_FILE":    writeTestFile(t, "password", "secret\n"),
+		"DB_PASSWORD_FILE": writeTestFile(t, "db_password", "db-secret\n"),
+		"PORT":             "8080",
+		"PORT_FILE":        writeTestFile(t, "port", "9090"),
+		"NAME":             writeTestFile(t, "name", "foo"),
+		"NAME_FILE":        "ignored",
+	}
+	cfg := &config{}
+	err := Load(cfg, NewFileVars(true), env)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", cfg.Password)
+	assert.Equal(t, "", cfg.User)
+	assert.Equal(t, "db-secret", cfg.Db.Password)
+	assert.Equal(t, 8080, cfg.Port)
+	assert.Equal(t, "foo", cfg.Name)
+
+	err = Load(cfg, NewFileVars(false), env)
+	require.NoError(t, err)
+	assert.Equal(t, "secret\n", cfg.Password)
+
+	err = Load(cfg, NewFileVarsSuffix("_PATH", true), MapEnvReader{
+		"PASSWORD_PATH":    writeTestFile(t, "password", "other"),
+		"DB_PASSWORD_PATH": writeTestFile(t, "db_password", "db-other"),
+		"PORT":             "8080",
+		"NAME":             writeTestFile(t, "name", "foo"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "other", cfg.Password)
+	assert.Equal(t, "db-other", cfg.Db.Password)
+
+	err = Load(cfg, env)
+	require.Error(t, err)
+	assert.Equal(t, "missing env var 'PASSWORD'\nmissing env var 'DB_PASSWORD'", err.Error())
+
+	env["PASSWORD_FILE"] = filepath.Join(t.TempDir(), "missing")
+	err = Load(cfg, NewFileVars(true), env)
+	require.Error(t, err)
+	var fe *FileError
+	require.True(t, errors.As(err, &fe))
+	assert.Equal(t, "PASSWORD_FILE", fe.Name)
+
+	err = Load(cfg, NewFileVars(true), NewFileVars(false))
+	require.Error(t, err)
+	assert.Equal(t, "multiple file vars options", err.Error())
+}
+
+func TestWrite_FileTag(t *testing.T) {
+	type config struct {
+		Name     string
+		Password string `env:"file=trim"`
+	}
+	var w bytes.Buffer
+	err := Write(&w, &config{Name: "foo", Password: "secret"})
+	require.NoError(t, err)
+	assert.Equal(t, "NAME=foo\nPASSWORD=<file path>\n", w.String())
+
+	w.Reset()
+	err = Example(&w, &config{})
+	require.NoError(t, err)
+	assert.Equal(t, "NAME=<string>\nPASSWORD=<file path>\n", w.String())
+}
@@ -0,0 +1,354 @@
+package cfgenv
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// CollectionFormat is an option that can be passed to Load or LoadAs (or Write and Example)
+// and determines the default format of slice and map env var values
+//
+// The format can also be set on individual fields using the tag `env:"format=delimited|json|query|lines|csv"`
+//
+// A default format is only used for fields it supports (e.g. CollectionFormatQuery is only used for maps), whereas a
+// format set using the tag must be supported by the field
+type CollectionFormat int
+
+const (
+	// CollectionFormatDelimited items are split by delimiters (and map keys & values by separator), e.g. "a,b" or "a:1,b:2"
+	// - see env tags `delimiter`, `separator`, `delims` and `quoted` (the default)
+	CollectionFormatDelimited CollectionFormat = iota
+	// CollectionFormatJson slices are JSON arrays and maps are JSON objects (or, for sets, JSON arrays), e.g. `["a","b"]` or `{"a":1,"b":2}`
+	// - including nested collections
+	CollectionFormatJson
+	// CollectionFormatQuery maps are query strings, e.g. "team=core&tier=1" (for maps of slices, keys may be repeated)
+	CollectionFormatQuery
+	// CollectionFormatLines items are on separate lines (blank lines are ignored)
+	// - multi-line values cannot be written by the default Formatter (see NewEnvFileFormatter), so pass a Formatter that
+	// supports them (e.g. NewDotenvFormatter or NewJsonFormatter) when writing
+	CollectionFormatLines
+	// CollectionFormatCsv items are a CSV record (using the field delimiter, if a single character), e.g. `a,"b,c"`
+	CollectionFormatCsv
+)
+
+var collectionFormatNames = map[string]CollectionFormat{
+	"delimited": CollectionFormatDelimited,
+	"json":      CollectionFormatJson,
+	"query":     CollectionFormatQuery,
+	"lines":     CollectionFormatLines,
+	"csv":       CollectionFormatCsv,
+}
+
+func formatName(f CollectionFormat) string {
+	for n, cf := range collectionFormatNames {
+		if cf == f {
+			return n
+		}
+	}
+	return ""
+}
+
+// supports determines whether the format supports the collection type
+func (f CollectionFormat) supports(t reflect.Type) bool {
+	switch {
+	case f == CollectionFormatDelimited:
+		return true
+	case !isCollectionType(t):
+		return false
+	case f == CollectionFormatJson:
+		return true
+	case f == CollectionFormatQuery:
+		return t.Kind() == reflect.Map && (!isCollectionType(t.Elem()) || (t.Elem().Kind() == reflect.Slice && !isCollectionType(t.Elem().Elem())))
+	}
+	return !isCollectionType(t.Elem())
+}
+
+// formatType returns the collection type to which the field format applies (for prefix and match maps, the map value type)
+func (fi *fieldInfo) formatType(fld reflect.StructField) reflect.Type {
+	if fi.isPrefixedMap || fi.isMatchedMap {
+		return fld.Type.Elem()
+	}
+	return fld.Type
+}
+
+// setFormatted sets a slice or map from a value in the field's (non-delimited) format
+func setFormatted(name string, raw string, fld reflect.StructField, fi *fieldInfo, fv reflect.Value) error {
+	if raw == "" {
+		return nil
+	}
+	switch fi.format {
+	case CollectionFormatJson:
+		return setJsonCollection(name, []byte(raw), fld, fi, fv)
+	case CollectionFormatQuery:
+		return setQueryMap(name, raw, fld, fi, fv)
+	case CollectionFormatLines:
+		vs := make([]string, 0)
+		for _, line := range strings.Split(raw, "\n") {
+			if line = strings.TrimSuffix(line, "\r"); strings.TrimSpace(line) != "" {
+				vs = append(vs, line)
+			}
+		}
+		return setFormattedItems(name, vs, fld, fi, fv)
+	}
+	r := csv.NewReader(strings.NewReader(raw))
+	r.Comma = fi.csvComma()
+	vs, err := r.Read()
+	if err != nil {
+		return &ParseError{Name: name, Type: fv.Type(), Err: err, msg: "is not a valid CSV record"}
+	}
+	return setFormattedItems(name, vs, fld, fi, fv)
+}
+
+func setFormattedItems(name string, vs []string, fld reflect.StructField, fi *fieldInfo, fv reflect.Value) error {
+	if fv.Kind() == reflect.Slice {
+		return setSliceItems(name, vs, fld, fi, fv, 0)
+	}
+	return setMapItems(name, vs, fld, fi, fv, 0)
+}
+
+// csvComma returns the CSV field delimiter - the field delimiter if it is a single character, otherwise a comma
+func (fi *fieldInfo) csvComma() rune {
+	if r, n := utf8.DecodeRuneInString(fi.delimiter); n > 0 && n == len(fi.delimiter) {
+		return r
+	}
+	return ','
+}
+
+func setQueryMap(name string, raw string, fld reflect.StructField, fi *fieldInfo, fv reflect.Value) error {
+	values, err := url.ParseQuery(raw)
+	if err != nil {
+		return &ParseError{Name: name, Type: fv.Type(), Err: err, msg: "is not a valid query string"}
+	}
+	m := reflect.MakeMap(fv.Type())
+	vt := fv.Type().Elem()
+	for k, vs := range values {
+		kv := reflect.New(fv.Type().Key()).Elem()
+		if err = setValueAt(name, k, fld, fi, kv, 1); err != nil {
+			return err
+		}
+		vv := reflect.New(vt).Elem()
+		switch {
+		case isSetItemType(vt):
+		case vt.Kind() == reflect.Bool && vs[0] == "":
+			vv.SetBool(true)
+		case vt.Kind() == reflect.Slice && isCollectionType(vt):
+			err = setSliceItems(name, vs, fld, fi, vv, 1)
+		default:
+			err = setItemValue(name, vs[0], fld, fi, vv, 1)
+		}
+		if err != nil {
+			return err
+		}
+		m.SetMapIndex(kv, vv)
+	}
+	fv.Set(m)
+	return nil
+}
+
+// setJsonCollection sets a slice from a JSON array - or a map from a JSON object (or, for sets, a JSON array)
+func setJsonCollection(name string, data []byte, fld reflect.StructField, fi *fieldInfo, fv reflect.Value) error {
+	t := fv.Type()
+	isArray := bytes.HasPrefix(bytes.TrimSpace(data), []byte("["))
+	if t.Kind() == reflect.Slice || (isArray && (isSetItemType(t.Elem()) || t.Elem().Kind() == reflect.Bool)) {
+		var items []json.RawMessage
+		err := json.Unmarshal(data, &items)
+		if err != nil {
+			return &ParseError{Name: name, Type: t, Err: err, msg: "is not a valid JSON array"}
+		} else if items == nil {
+			return nil
+		}
+		if t.Kind() == reflect.Slice {
+			sl := reflect.MakeSlice(t, len(items), len(items))
+			for i, item := range items {
+				if err = setJsonItem(name, item, fld, fi, sl.Index(i), false); err != nil {
+					return err
+				}
+			}
+			fv.Set(sl)
+			return nil
+		}
+		m := reflect.MakeMap(t)
+		for _, item := range items {
+			kv := reflect.New(t.Key()).Elem()
+			if err = setJsonItem(name, item, fld, fi, kv, false); err != nil {
+				return err
+			}
+			vv := reflect.New(t.Elem()).Elem()
+			if vv.Kind() == reflect.Bool {
+				vv.SetBool(true)
+			}
+			m.SetMapIndex(kv, vv)
+		}
+		fv.Set(m)
+		return nil
+	}
+	var items map[string]json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return &ParseError{Name: name, Type: t, Err: err, msg: "is not a valid JSON object"}
+	} else if items == nil {
+		return nil
+	}
+	m := reflect.MakeMap(t)
+	for k, item := range items {
+		kv := reflect.New(t.Key()).Elem()
+		if err := setValueAt(name, k, fld, fi, kv, 1); err != nil {
+			return err
+		}
+		vv := reflect.New(t.Elem()).Elem()
+		if err := setJsonItem(name, item, fld, fi, vv, true); err != nil {
+			return err
+		}
+		m.SetMapIndex(kv, vv)
+	}
+	fv.Set(m)
+	return nil
+}
+
+// setJsonItem sets a JSON array or object item - JSON strings are unquoted and other JSON values (e.g. numbers) are used as is
+func setJsonItem(name string, data json.RawMessage, fld reflect.StructField, fi *fieldInfo, iv reflect.Value, isMapValue bool) error {
+	if isCollectionType(iv.Type()) {
+		return setJsonCollection(name, data, fld, fi, iv)
+	} else if string(data) == "null" {
+		return nil
+	}
+	raw := string(data)
+	if strings.HasPrefix(raw, `"`) {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return &ParseError{Name: name, Type: iv.Type(), Err: err}
+		}
+	}
+	if isMapValue {
+		return setItemValue(name, raw, fld, fi, iv, 1)
+	}
+	return setValueAt(name, raw, fld, fi, iv, 1)
+}
+
+// formattedValueString returns the string of a slice or map in the field's (non-delimited) format
+func formattedValueString(fv reflect.Value, fi *fieldInfo) (string, error) {
+	switch fi.format {
+	case CollectionFormatJson:
+		data, err := json.Marshal(fi.jsonValue(fv))
+		return string(data), err
+	case CollectionFormatQuery:
+		values := url.Values{}
+		for _, mk := range fv.MapKeys() {
+			mv := fv.MapIndex(mk)
+			switch {
+			case isSetItemType(mv.Type()):
+				values.Add(fi.itemString(mk), "")
+			case isCollectionType(mv.Type()):
+				for i := 0; i < mv.Len(); i++ {
+					values.Add(fi.itemString(mk), fi.itemString(mv.Index(i)))
+				}
+			default:
+				values.Add(fi.itemString(mk), fi.itemString(mv))
+			}
+		}
+		return values.Encode(), nil
+	}
+	items := formattedItems(fv, fi)
+	if fi.format == CollectionFormatLines {
+		return strings.Join(items, "\n"), nil
+	}
+	var sb strings.Builder
+	w := csv.NewWriter(&sb)
+	w.Comma = fi.csvComma()
+	_ = w.Write(items)
+	w.Flush()
+	return strings.TrimSuffix(sb.String(), "\n"), nil
+}
+
+// formattedItems returns the item strings of a slice or map (map items as key/value pairs, or keys for sets)
+func formattedItems(fv reflect.Value, fi *fieldInfo) []string {
+	items := make([]string, 0)
+	if fv.Kind() == reflect.Slice {
+		for i := 0; i < fv.Len(); i++ {
+			items = append(items, fi.quoteItem(fi.itemString(fv.Index(i))))
+		}
+		return items
+	}
+	isSet := isSetItemType(fv.Type().Elem())
+	for _, mk := range fv.MapKeys() {
+		if isSet {
+			items = append(items, fi.quoteItem(fi.itemString(mk)))
+		} else {
+			items = append(items, fi.quoteItem(fi.itemString(mk))+fi.separator+fi.quoteItem(fi.itemString(fv.MapIndex(mk))))
+		}
+	}
+	return items
+}
+
+// jsonValue returns a value for marshalling to JSON - where slices and maps are arrays and objects (sets are arrays),
+// numbers & bools are JSON numbers & bools and other values (including enums) are JSON strings
+func (fi *fieldInfo) jsonValue(v reflect.Value) any {
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		return nil
+	} else if s, ok := fi.enumValueString(v); ok {
+		return s
+	}
+	switch {
+	case isCollectionType(v.Type()) && v.Kind() == reflect.Slice:
+		result := make([]any, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			result = append(result, fi.jsonValue(v.Index(i)))
+		}
+		return result
+	case isCollectionType(v.Type()) && isSetItemType(v.Type().Elem()):
+		keys := make([]string, 0, v.Len())
+		for _, mk := range v.MapKeys() {
+			keys = append(keys, fi.itemString(mk))
+		}
+		sort.Strings(keys)
+		result := make([]any, 0, len(keys))
+		for _, k := range keys {
+			result = append(result, k)
+		}
+		return result
+	case isCollectionType(v.Type()):
+		result := make(map[string]any, v.Len())
+		for _, mk := range v.MapKeys() {
+			result[fi.itemString(mk)] = fi.jsonValue(v.MapIndex(mk))
+		}
+		return result
+	case isTextType(v.Type()) || reflect.Indirect(v).Type() == durationType:
+		return itemString(v)
+	}
+	switch reflect.Indirect(v).Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return json.RawMessage(itemString(v))
+	}
+	return itemString(v)
+}
+
+// exampleFormatted returns an example value for a slice or map in the field's (non-delimited) format
+func exampleFormatted(t reflect.Type, fi *fieldInfo) string {
+	isSet := t.Kind() == reflect.Map && isSetItemType(t.Elem())
+	switch fi.format {
+	case CollectionFormatJson:
+		if t.Kind() == reflect.Slice {
+			return `["value","value",...]`
+		} else if isSet {
+			return `["key","key",...]`
+		}
+		return `{"key":"value","key":"value",...}`
+	case CollectionFormatQuery:
+		if isSet {
+			return "key&key&..."
+		}
+		return "key=value&key=value&..."
+	case CollectionFormatLines:
+		return "<one item per line>"
+	}
+	comma := string(fi.csvComma())
+	if t.Kind() == reflect.Slice || isSet {
+		return "value" + comma + "value" + comma + "..."
+	}
+	return "key" + fi.separator + "value" + comma + "key" + fi.separator + "value" + comma + "..."
+}
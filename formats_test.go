@@ -0,0 +1,312 @@
+package cfgenv
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestLoad_CollectionFormats(t *testing.T) {
+	type config struct {
+		Allowed  []string                  `env:"format=json"`
+		Limits   map[string]int            `env:"format=json"`
+		Matrix   [][]int                   `env:"format=json"`
+		Routes   map[string][]string       `env:"format=json"`
+		Tags     map[string]struct{}       `env:"format=json"`
+		Timeouts map[string]time.Duration  `env:"format=json"`
+		Labels   map[string]string         `env:"format=query"`
+		Multi    map[string][]string       `env:"format=query"`
+		Flags    map[string]bool           `env:"format=query"`
+		Hosts    []string                  `env:"format=lines"`
+		Pairs    map[string]int            `env:"format=lines"`
+		Names    []string                  `env:"format=csv"`
+		Semis    []string                  `env:"format=csv,delim=;"`
+		Prefixed map[string][]int          `env:"prefix=PFX_,format=json"`
+		Plain    []string                  `env:"format=delimited"`
+		Nested   map[string]map[string]int `env:"format=json"`
+	}
+	cfg := &config{}
+	err := Load(cfg, NewDurationSetter(), MapEnvReader{
+		"ALLOWED":  `["a","b,c"]`,
+		"LIMITS":   `{"free":10,"pro":100}`,
+		"MATRIX":   `[[1,2],[3]]`,
+		"ROUTES":   `{"a":["x","y"],"b":null}`,
+		"TAGS":     `["x","y"]`,
+		"TIMEOUTS": `{"read":"5s"}`,
+		"LABELS":   "team=core&tier=1&name=a%20b",
+		"MULTI":    "a=1&a=2&b=3",
+		"FLAGS":    "x&y=false",
+		"HOSTS":    "a\r\n\nb\n",
+		"PAIRS":    "a:1\nb:2",
+		"NAMES":    `a,"b,c",d`,
+		"SEMIS":    `a;"b;c"`,
+		"PFX_X":    `[1,2]`,
+		"PLAIN":    `a,b`,
+		"NESTED":   `{"a":{"x":1}}`,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b,c"}, cfg.Allowed)
+	assert.Equal(t, map[string]int{"free": 10, "pro": 100}, cfg.Limits)
+	assert.Equal(t, [][]int{{1, 2}, {3}}, cfg.Matrix)
+	assert.Equal(t, map[string][]string{"a": {"x", "y"}, "b": nil}, cfg.Routes)
+	assert.Equal(t, map[string]struct{}{"x": {}, "y": {}}, cfg.Tags)
+	assert.Equal(t, map[string]time.Duration{"read": 5 * time.Second}, cfg.Timeouts)
+	assert.Equal(t, map[string]string{"team": "core", "tier": "1", "name": "a b"}, cfg.Labels)
+	assert.Equal(t, map[string][]string{"a": {"1", "2"}, "b": {"3"}}, cfg.Multi)
+	assert.Equal(t, map[string]bool{"x": true, "y": false}, cfg.Flags)
+	assert.Equal(t, []string{"a", "b"}, cfg.Hosts)
+	assert.Equal(t, map[string]int{"a": 1, "b": 2}, cfg.Pairs)
+	assert.Equal(t, []string{"a", "b,c", "d"}, cfg.Names)
+	assert.Equal(t, []string{"a", "b;c"}, cfg.Semis)
+	assert.Equal(t, map[string][]int{"X": {1, 2}}, cfg.Prefixed)
+	assert.Equal(t, []string{"a", "b"}, cfg.Plain)
+	assert.Equal(t, map[string]map[string]int{"a": {"x": 1}}, cfg.Nested)
+}
+
+func TestLoad_CollectionFormatOption(t *testing.T) {
+	type config struct {
+		Allowed []string
+		Limits  map[string]int
+		Name    string
+		Plain   []string `env:"format=delimited"`
+	}
+	cfg := &config{}
+	err := Load(cfg, CollectionFormatJson, MapEnvReader{
+		"ALLOWED": `["a","b"]`,
+		"LIMITS":  `{"free":10}`,
+		"NAME":    `["x"]`,
+		"PLAIN":   `a,b`,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, cfg.Allowed)
+	assert.Equal(t, map[string]int{"free": 10}, cfg.Limits)
+	assert.Equal(t, `["x"]`, cfg.Name)
+	assert.Equal(t, []string{"a", "b"}, cfg.Plain)
+
+	// query format is only used for maps...
+	cfg = &config{}
+	err = Load(cfg, CollectionFormatQuery, MapEnvReader{
+		"ALLOWED": `a,b`,
+		"LIMITS":  `free=10`,
+		"NAME":    `x`,
+		"PLAIN":   `a,b`,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, cfg.Allowed)
+	assert.Equal(t, map[string]int{"free": 10}, cfg.Limits)
+
+	err = Load(cfg, CollectionFormatJson, CollectionFormatJson)
+	require.Error(t, err)
+	assert.Equal(t, "multiple collection format options", err.Error())
+}
+
+func TestLoad_CollectionFormats_Errors(t *testing.T) {
+	testCases := []struct {
+		cfg         any
+		env         MapEnvReader
+		expectError string
+	}{
+		{
+			cfg: &struct {
+				Test []string `env:"format=xml"`
+			}{},
+			expectError: "env tag 'format' on field 'Test' - invalid format 'xml' (must be delimited, json, query, lines or csv)",
+		},
+		{
+			cfg: &struct {
+				Test string `env:"format=json"`
+			}{},
+			expectError: "cannot use env tag 'format=json' on field 'Test' (unsupported collection type)",
+		},
+		{
+			cfg: &struct {
+				Test []string `env:"format=query"`
+			}{},
+			expectError: "cannot use env tag 'format=query' on field 'Test' (unsupported collection type)",
+		},
+		{
+			cfg: &struct {
+				Test [][]string `env:"format=lines"`
+			}{},
+			expectError: "cannot use env tag 'format=lines' on field 'Test' (unsupported collection type)",
+		},
+		{
+			cfg: &struct {
+				Test []string `env:"format"`
+			}{},
+			expectError: "cannot use env tag 'format' without value on field 'Test' (use quotes if necessary)",
+		},
+		{
+			cfg: &struct {
+				Test []int `env:"format=json"`
+			}{},
+			env:         MapEnvReader{"TEST": `[1,"x"]`},
+			expectError: "env var 'TEST' is not an int",
+		},
+		{
+			cfg: &struct {
+				Test []int `env:"format=json"`
+			}{},
+			env:         MapEnvReader{"TEST": `{"a":1}`},
+			expectError: "env var 'TEST' is not a valid JSON array",
+		},
+		{
+			cfg: &struct {
+				Test map[string]int `env:"format=json"`
+			}{},
+			env:         MapEnvReader{"TEST": `[1]`},
+			expectError: "env var 'TEST' is not a valid JSON object",
+		},
+		{
+			cfg: &struct {
+				Test map[string]int `env:"format=query"`
+			}{},
+			env:         MapEnvReader{"TEST": `a=%zz`},
+			expectError: "env var 'TEST' is not a valid query string",
+		},
+		{
+			cfg: &struct {
+				Test map[string]int `env:"format=query"`
+			}{},
+			env:         MapEnvReader{"TEST": `a=x`},
+			expectError: "env var 'TEST' is not an int",
+		},
+		{
+			cfg: &struct {
+				Test []string `env:"format=csv"`
+			}{},
+			env:         MapEnvReader{"TEST": `a,"b`},
+			expectError: "env var 'TEST' is not a valid CSV record",
+		},
+		{
+			cfg: &struct {
+				Test map[string]int `env:"format=lines"`
+			}{},
+			env:         MapEnvReader{"TEST": "a:1\nb"},
+			expectError: "env var 'TEST' contains invalid key/value pair - b",
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("[%d]", i+1), func(t *testing.T) {
+			env := tc.env
+			if env == nil {
+				env = MapEnvReader{}
+			}
+			err := Load(tc.cfg, env)
+			require.Error(t, err)
+			assert.Equal(t, tc.expectError, err.Error())
+		})
+	}
+}
+
+func TestWrite_CollectionFormats(t *testing.T) {
+	type config struct {
+		Allowed []string            `env:"format=json"`
+		Limits  map[string]int      `env:"format=json"`
+		Routes  map[string][]string `env:"format=json"`
+		Tags    map[string]struct{} `env:"format=json"`
+		Labels  map[string]string   `env:"format=query"`
+		Hosts   []string            `env:"format=lines"`
+		Names   []string            `env:"format=csv"`
+	}
+	cfg := &config{
+		Allowed: []string{"a", "b,c"},
+		Limits:  map[string]int{"pro": 100, "free": 10},
+		Routes:  map[string][]string{"a": {"x"}},
+		Tags:    map[string]struct{}{"y": {}, "x": {}},
+		Labels:  map[string]string{"tier": "1", "team": "core"},
+		Hosts:   []string{"a"},
+		Names:   []string{"a", "b,c"},
+	}
+	var w bytes.Buffer
+	err := Write(&w, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, `ALLOWED=["a","b,c"]
+LIMITS={"free":10,"pro":100}
+ROUTES={"a":["x"]}
+TAGS=["x","y"]
+LABELS=team=core&tier=1
+HOSTS=a
+NAMES=a,"b,c"
+`, w.String())
+
+	type roundTrip struct {
+		Allowed []string            `env:"format=json"`
+		Limits  map[string]int      `env:"format=json"`
+		Routes  map[string][]string `env:"format=json"`
+		Tags    map[string]struct{} `env:"format=json"`
+		Labels  map[string]string   `env:"format=query"`
+		Names   []string            `env:"format=csv"`
+	}
+	rt := &roundTrip{
+		Allowed: cfg.Allowed,
+		Limits:  cfg.Limits,
+		Routes:  cfg.Routes,
+		Tags:    cfg.Tags,
+		Labels:  cfg.Labels,
+		Names:   cfg.Names,
+	}
+	w.Reset()
+	err = Write(&w, rt)
+	require.NoError(t, err)
+	loaded := &roundTrip{}
+	err = Load(loaded, NewEnvFileReader(&w, nil))
+	require.NoError(t, err)
+	assert.Equal(t, rt, loaded)
+
+	w.Reset()
+	err = Example(&w, &config{})
+	require.NoError(t, err)
+	assert.Equal(t, `ALLOWED=["value","value",...]
+LIMITS={"key":"value","key":"value",...}
+ROUTES={"key":"value","key":"value",...}
+TAGS=["key","key",...]
+LABELS=key=value&key=value&...
+HOSTS=<one item per line>
+NAMES=value,value,...
+`, w.String())
+}
+
+func TestWrite_CollectionFormatLines(t *testing.T) {
+	type config struct {
+		Hosts []string `env:"format=lines"`
+	}
+	cfg := &config{Hosts: []string{"x", "y", "z"}}
+	var w bytes.Buffer
+	err := Write(&w, cfg)
+	require.Error(t, err)
+	assert.Equal(t, "env var 'HOSTS' value contains a newline (not supported by env file)", err.Error())
+	assert.Empty(t, w.String())
+
+	err = Write(&w, cfg, NewDotenvFormatter())
+	require.NoError(t, err)
+	assert.Equal(t, "HOSTS=\"x\\ny\\nz\"\n", w.String())
+
+	w.Reset()
+	err = Write(&w, cfg, NewJsonFormatter())
+	require.NoError(t, err)
+	m := MapEnvReader{}
+	require.NoError(t, json.Unmarshal(w.Bytes(), &m))
+	loaded := &config{}
+	err = Load(loaded, m)
+	require.NoError(t, err)
+	assert.Equal(t, cfg, loaded)
+}
+
+func TestWrite_CollectionFormatJson_Error(t *testing.T) {
+	type config struct {
+		Ratios []float64 `env:"format=json"`
+	}
+	var w bytes.Buffer
+	err := Write(&w, &config{Ratios: []float64{1, math.NaN()}})
+	require.Error(t, err)
+	var me *json.MarshalerError
+	assert.True(t, errors.As(err, &me))
+	assert.Empty(t, w.String())
+}
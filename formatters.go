@@ -0,0 +1,307 @@
+package cfgenv
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+)
+
+// Formatter is an option that can be passed to Write, Example or ExampleOf
+// and determines the output format of the env vars written
+//
+// If no Formatter is passed, env vars are written as KEY=value lines (as read by NewEnvFileReader) - see
+// NewEnvFileFormatter
+type Formatter interface {
+	// Format writes the env vars (in the order they are to be written)
+	Format(w io.Writer, vars []EnvVar) error
+}
+
+// EnvVar is an env var written by Write, Example or ExampleOf (see Formatter)
+type EnvVar struct {
+	// Name is the env var name
+	Name string
+	// Value is the unquoted value - for Example, an example value (e.g. "<string>") and, for secrets, the redacted
+	// value (see Redactor)
+	Value string
+	// Secret denotes the env var is a secret (see Secret, env tag `secret` and SecretNamesOption)
+	Secret bool
+	// Example denotes the value is an example (written by Example or ExampleOf) rather than an actual value
+	Example bool
+}
+
+// lineFormatter is a Formatter that writes each env var as a line
+type lineFormatter struct {
+	line func(v EnvVar) (string, error)
+}
+
+func (f *lineFormatter) Format(w io.Writer, vars []EnvVar) error {
+	var sb strings.Builder
+	for _, v := range vars {
+		line, err := f.line(v)
+		if err != nil {
+			return err
+		}
+		sb.WriteString(line + "\n")
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+var defaultFormatter = NewEnvFileFormatter()
+
+// NewEnvFileFormatter creates a new Formatter that writes env vars as KEY=value lines - as read by NewEnvFileReader
+// (actual values are only quoted where they both start and end with the same quote)
+//
+// values containing newlines cannot be read by NewEnvFileReader - so cannot be written (and are an error), e.g. for
+// fields with `format=lines` - use another Formatter (e.g. NewDotenvFormatter) for multi-line values
+//
+// This is the default Formatter
+func NewEnvFileFormatter() Formatter {
+	return &lineFormatter{line: func(v EnvVar) (string, error) {
+		if strings.ContainsAny(v.Value, "\r\n") {
+			return "", newlineError(v.Name, "env file")
+		} else if v.Example {
+			return v.Name + "=" + v.Value, nil
+		}
+		return v.Name + "=" + envFileQuoted(v.Value), nil
+	}}
+}
+
+// NewDotenvFormatter creates a new Formatter that writes env vars as .env file KEY=value lines - where values are
+// quoted (and escaped) where needed, e.g.
+//
+//	GREETING='hello world'
+//	MESSAGE="it's\nmultiline"
+func NewDotenvFormatter() Formatter {
+	return &lineFormatter{line: func(v EnvVar) (string, error) {
+		return v.Name + "=" + dotenvQuoted(v.Value), nil
+	}}
+}
+
+// NewShellFormatter creates a new Formatter that writes env vars as shell export statements - where values are single
+// quoted (and safely escaped), e.g.
+//
+//	export GREETING='it'\''s'
+func NewShellFormatter() Formatter {
+	return &lineFormatter{line: func(v EnvVar) (string, error) {
+		return "export " + v.Name + "=" + shellQuoted(v.Value), nil
+	}}
+}
+
+// NewPropertiesFormatter creates a new Formatter that writes env vars as Java properties file key=value lines - where
+// keys and values are escaped (including non-ASCII characters as \uXXXX)
+func NewPropertiesFormatter() Formatter {
+	return &lineFormatter{line: func(v EnvVar) (string, error) {
+		return propertiesEscaped(v.Name, true) + "=" + propertiesEscaped(v.Value, false), nil
+	}}
+}
+
+// NewSystemdFormatter creates a new Formatter that writes env vars as systemd EnvironmentFile KEY=value lines - where
+// values are double quoted (and escaped) where needed
+func NewSystemdFormatter() Formatter {
+	return &lineFormatter{line: func(v EnvVar) (string, error) {
+		return v.Name + "=" + systemdQuoted(v.Value), nil
+	}}
+}
+
+// NewDockerfileFormatter creates a new Formatter that writes env vars as Dockerfile ENV instructions - where values are
+// double quoted (and escaped), e.g.
+//
+//	ENV GREETING="hello world"
+//
+// values containing newlines cannot be written (and are an error)
+func NewDockerfileFormatter() Formatter {
+	return &lineFormatter{line: func(v EnvVar) (string, error) {
+		if strings.ContainsAny(v.Value, "\r\n") {
+			return "", newlineError(v.Name, "Dockerfile ENV")
+		}
+		return "ENV " + v.Name + "=" + dockerfileQuoted(v.Value), nil
+	}}
+}
+
+func newlineError(name string, format string) error {
+	return fmt.Errorf("env var '%s' value contains a newline (not supported by %s)", name, format)
+}
+
+type jsonFormatter struct{}
+
+// NewJsonFormatter creates a new Formatter that writes env vars as a JSON object (with keys in written order and all
+// values as strings)
+func NewJsonFormatter() Formatter {
+	return &jsonFormatter{}
+}
+
+func (f *jsonFormatter) Format(w io.Writer, vars []EnvVar) error {
+	if len(vars) == 0 {
+		_, err := io.WriteString(w, "{}\n")
+		return err
+	}
+	var sb strings.Builder
+	sb.WriteString("{\n")
+	for i, v := range vars {
+		sb.WriteString("  " + jsonQuoted(v.Name) + ": " + jsonQuoted(v.Value))
+		if i < len(vars)-1 {
+			sb.WriteString(",")
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("}\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+type yamlFormatter struct {
+	key    string
+	indent string
+	value  func(s string) string
+}
+
+// NewYamlFormatter creates a new Formatter that writes env vars as a YAML mapping (with all values as double quoted
+// strings)
+func NewYamlFormatter() Formatter {
+	return &yamlFormatter{value: jsonQuoted}
+}
+
+// NewComposeFormatter creates a new Formatter that writes env vars as a docker-compose service `environment:` block
+// (with all values as double quoted strings and `$` escaped as `$$`), e.g.
+//
+//	environment:
+//	  DB_HOST: "localhost"
+func NewComposeFormatter() Formatter {
+	return &yamlFormatter{key: "environment", indent: "  ", value: func(s string) string {
+		return jsonQuoted(strings.ReplaceAll(s, "$", "$$"))
+	}}
+}
+
+func (f *yamlFormatter) Format(w io.Writer, vars []EnvVar) error {
+	var sb strings.Builder
+	if f.key != "" {
+		sb.WriteString(f.key + ":")
+		if len(vars) == 0 {
+			sb.WriteString(" {}")
+		}
+		sb.WriteString("\n")
+	} else if len(vars) == 0 {
+		sb.WriteString("{}\n")
+	}
+	for _, v := range vars {
+		sb.WriteString(f.indent + yamlKey(v.Name) + ": " + f.value(v.Value) + "\n")
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// envFileQuoted wraps a value in single quotes where it would otherwise be changed when read by NewEnvFileReader - i.e.
+// it starts or ends with whitespace (which would be trimmed) or it both starts and ends with the same quote (which would
+// be stripped)
+//
+// NewEnvFileReader only strips the outer quotes - so the value itself never needs escaping
+func envFileQuoted(s string) string {
+	if s == "" {
+		return s
+	} else if first, last := s[0], s[len(s)-1]; first == ' ' || first == '\t' || last == ' ' || last == '\t' ||
+		((first == '"' || first == '\'') && last == first) {
+		return "'" + s + "'"
+	}
+	return s
+}
+
+// unquotedSafeRegex matches values that can be written without quotes
+var unquotedSafeRegex = regexp.MustCompile(`^[\w./:@%+,=-]*$`)
+
+var dotenvEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "\n", `\n`, "\r", `\r`)
+
+// dotenvQuoted quotes a value (where needed) for a .env file - single quoted (literal) where possible, otherwise
+// double quoted with escapes
+func dotenvQuoted(s string) string {
+	if unquotedSafeRegex.MatchString(s) {
+		return s
+	} else if !strings.ContainsAny(s, "'\r\n") {
+		return "'" + s + "'"
+	}
+	return `"` + dotenvEscaper.Replace(s) + `"`
+}
+
+// shellQuoted single quotes a value for a POSIX shell (each single quote within the value closes the quoting, is
+// escaped with a backslash and reopens the quoting)
+func shellQuoted(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+var systemdEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "`", "\\`")
+
+// systemdQuoted double quotes a value (where needed) for a systemd EnvironmentFile - newlines are retained within the
+// quotes (as supported by systemd)
+func systemdQuoted(s string) string {
+	if unquotedSafeRegex.MatchString(s) {
+		return s
+	}
+	return `"` + systemdEscaper.Replace(s) + `"`
+}
+
+var dockerfileEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`)
+
+// dockerfileQuoted double quotes a value for a Dockerfile ENV instruction
+func dockerfileQuoted(s string) string {
+	return `"` + dockerfileEscaper.Replace(s) + `"`
+}
+
+// propertiesEscaped escapes a key or value for a Java properties file
+func propertiesEscaped(s string, key bool) string {
+	var sb strings.Builder
+	for i, r := range s {
+		switch {
+		case r == '\\':
+			sb.WriteString(`\\`)
+		case r == '\t':
+			sb.WriteString(`\t`)
+		case r == '\n':
+			sb.WriteString(`\n`)
+		case r == '\r':
+			sb.WriteString(`\r`)
+		case r == '\f':
+			sb.WriteString(`\f`)
+		case r == ' ' && (key || i == 0):
+			sb.WriteString(`\ `)
+		case key && strings.ContainsRune("=:#!", r):
+			sb.WriteRune('\\')
+			sb.WriteRune(r)
+		case r < 0x20 || r > 0x7e:
+			for _, u := range utf16.Encode([]rune{r}) {
+				sb.WriteString(fmt.Sprintf(`\u%04x`, u))
+			}
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// jsonQuoted returns the JSON string of a value (without escaping HTML characters - e.g. so that examples such as
+// "<string>" remain readable)
+func jsonQuoted(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+var yamlPlainKeyRegex = regexp.MustCompile(`^[A-Za-z_][\w.-]*$`)
+
+// yamlReservedKeys are the plain scalars that YAML (1.1) would not read as strings
+var yamlReservedKeys = map[string]bool{
+	"y": true, "n": true, "yes": true, "no": true, "on": true, "off": true, "true": true, "false": true, "null": true,
+}
+
+// yamlKey returns a mapping key for YAML - quoted where needed
+func yamlKey(s string) string {
+	if yamlPlainKeyRegex.MatchString(s) && !yamlReservedKeys[strings.ToLower(s)] {
+		return s
+	}
+	return jsonQuoted(s)
+}
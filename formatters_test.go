@@ -0,0 +1,324 @@
+package cfgenv
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/url"
+	"testing"
+)
+
+type testFormatterConfig struct {
+	Host     string
+	Greeting string
+	Message  *string
+	Missing  *string
+	Url      *url.URL
+	Password Secret[string]
+	Extra    map[string]string `env:"prefix=EXTRA_"`
+}
+
+func newTestFormatterConfig() *testFormatterConfig {
+	msg := "it's \"$HOME\"\nnext"
+	u, _ := url.Parse("https://example.com/a?b=c")
+	return &testFormatterConfig{
+		Host:     "localhost",
+		Greeting: "hello world",
+		Message:  &msg,
+		Url:      u,
+		Password: NewSecret("foo"),
+		Extra:    map[string]string{"B": "b", "A": "café"},
+	}
+}
+
+func TestWrite_Formatters(t *testing.T) {
+	testCases := []struct {
+		formatter   Formatter
+		expect      string
+		expectError string
+	}{
+		{
+			formatter:   nil,
+			expectError: "env var 'MESSAGE' value contains a newline (not supported by env file)",
+		},
+		{
+			formatter: NewDotenvFormatter(),
+			expect: `HOST=localhost
+GREETING='hello world'
+MESSAGE="it's \"\$HOME\"\nnext"
+URL='https://example.com/a?b=c'
+PASSWORD='******'
+EXTRA_A='café'
+EXTRA_B=b
+`,
+		},
+		{
+			formatter: NewShellFormatter(),
+			expect: `export HOST='localhost'
+export GREETING='hello world'
+export MESSAGE='it'\''s "$HOME"
+next'
+export URL='https://example.com/a?b=c'
+export PASSWORD='******'
+export EXTRA_A='café'
+export EXTRA_B='b'
+`,
+		},
+		{
+			formatter: NewJsonFormatter(),
+			expect: `{
+  "HOST": "localhost",
+  "GREETING": "hello world",
+  "MESSAGE": "it's \"$HOME\"\nnext",
+  "URL": "https://example.com/a?b=c",
+  "PASSWORD": "******",
+  "EXTRA_A": "café",
+  "EXTRA_B": "b"
+}
+`,
+		},
+		{
+			formatter: NewYamlFormatter(),
+			expect: `HOST: "localhost"
+GREETING: "hello world"
+MESSAGE: "it's \"$HOME\"\nnext"
+URL: "https://example.com/a?b=c"
+PASSWORD: "******"
+EXTRA_A: "café"
+EXTRA_B: "b"
+`,
+		},
+		{
+			formatter: NewPropertiesFormatter(),
+			expect: `HOST=localhost
+GREETING=hello world
+MESSAGE=it's "$HOME"\nnext
+URL=https://example.com/a?b=c
+PASSWORD=******
+EXTRA_A=caf\u00e9
+EXTRA_B=b
+`,
+		},
+		{
+			formatter: NewSystemdFormatter(),
+			expect: `HOST=localhost
+GREETING="hello world"
+MESSAGE="it's \"\$HOME\"
+next"
+URL="https://example.com/a?b=c"
+PASSWORD="******"
+EXTRA_A="café"
+EXTRA_B=b
+`,
+		},
+		{
+			formatter: NewComposeFormatter(),
+			expect: `environment:
+  HOST: "localhost"
+  GREETING: "hello world"
+  MESSAGE: "it's \"$$HOME\"\nnext"
+  URL: "https://example.com/a?b=c"
+  PASSWORD: "******"
+  EXTRA_A: "café"
+  EXTRA_B: "b"
+`,
+		},
+		{
+			formatter:   NewDockerfileFormatter(),
+			expectError: "env var 'MESSAGE' value contains a newline (not supported by Dockerfile ENV)",
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("[%d]", i+1), func(t *testing.T) {
+			var w bytes.Buffer
+			err := Write(&w, newTestFormatterConfig(), tc.formatter)
+			if tc.expectError != "" {
+				require.Error(t, err)
+				assert.Equal(t, tc.expectError, err.Error())
+				assert.Empty(t, w.String())
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.expect, w.String())
+			}
+		})
+	}
+}
+
+func TestWrite_EnvFileFormatter(t *testing.T) {
+	cfg := newTestFormatterConfig()
+	msg := `it's "$HOME"`
+	cfg.Message = &msg
+	var w bytes.Buffer
+	err := Write(&w, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, `HOST=localhost
+GREETING=hello world
+MESSAGE=it's "$HOME"
+URL=https://example.com/a?b=c
+PASSWORD=******
+EXTRA_A=café
+EXTRA_B=b
+`, w.String())
+}
+
+func TestWrite_DockerfileFormatter(t *testing.T) {
+	cfg := newTestFormatterConfig()
+	cfg.Message = nil
+	cfg.Greeting = `say "hi" \ $USER`
+	var w bytes.Buffer
+	err := Write(&w, cfg, NewDockerfileFormatter())
+	require.NoError(t, err)
+	assert.Equal(t, `ENV HOST="localhost"
+ENV GREETING="say \"hi\" \\ \$USER"
+ENV URL="https://example.com/a?b=c"
+ENV PASSWORD="******"
+ENV EXTRA_A="café"
+ENV EXTRA_B="b"
+`, w.String())
+}
+
+func TestExample_Formatters(t *testing.T) {
+	type config struct {
+		Host     string `env:"optional,default=localhost"`
+		Port     int
+		Password Secret[string]
+	}
+	testCases := []struct {
+		formatter Formatter
+		expect    string
+	}{
+		{
+			formatter: NewEnvFileFormatter(),
+			expect:    "HOST=localhost\nPORT=0\nPASSWORD=<secret>\n",
+		},
+		{
+			formatter: NewDotenvFormatter(),
+			expect:    "HOST=localhost\nPORT=0\nPASSWORD='<secret>'\n",
+		},
+		{
+			formatter: NewJsonFormatter(),
+			expect:    "{\n  \"HOST\": \"localhost\",\n  \"PORT\": \"0\",\n  \"PASSWORD\": \"<secret>\"\n}\n",
+		},
+		{
+			formatter: NewYamlFormatter(),
+			expect:    "HOST: \"localhost\"\nPORT: \"0\"\nPASSWORD: \"<secret>\"\n",
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("[%d]", i+1), func(t *testing.T) {
+			var w bytes.Buffer
+			err := ExampleOf[config](&w, tc.formatter)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expect, w.String())
+		})
+	}
+}
+
+func TestWrite_Formatters_Empty(t *testing.T) {
+	type config struct {
+		Missing *string
+	}
+	testCases := []struct {
+		formatter Formatter
+		expect    string
+	}{
+		{formatter: NewEnvFileFormatter(), expect: ""},
+		{formatter: NewJsonFormatter(), expect: "{}\n"},
+		{formatter: NewYamlFormatter(), expect: "{}\n"},
+		{formatter: NewComposeFormatter(), expect: "environment: {}\n"},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("[%d]", i+1), func(t *testing.T) {
+			var w bytes.Buffer
+			err := Write(&w, &config{}, tc.formatter)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expect, w.String())
+		})
+	}
+}
+
+func TestWrite_JsonFormatter_Valid(t *testing.T) {
+	var w bytes.Buffer
+	err := Write(&w, newTestFormatterConfig(), NewJsonFormatter(), NewRevealSecrets())
+	require.NoError(t, err)
+	m := map[string]string{}
+	require.NoError(t, json.Unmarshal(w.Bytes(), &m))
+	assert.Equal(t, "it's \"$HOME\"\nnext", m["MESSAGE"])
+	assert.Equal(t, "foo", m["PASSWORD"])
+}
+
+func TestWrite_EnvFileFormatter_RoundTrip(t *testing.T) {
+	type config struct {
+		Value string
+	}
+	testCases := []struct {
+		value  string
+		expect string
+	}{
+		{value: "x", expect: "VALUE=x\n"},
+		{value: "", expect: "VALUE=\n"},
+		{value: " x ", expect: "VALUE=' x '\n"},
+		{value: "x ", expect: "VALUE='x '\n"},
+		{value: "\tx", expect: "VALUE='\tx'\n"},
+		{value: `"foo"`, expect: `VALUE='"foo"'` + "\n"},
+		{value: `'foo'`, expect: `VALUE=''foo''` + "\n"},
+		{value: `"foo`, expect: `VALUE="foo` + "\n"},
+		{value: `'foo`, expect: `VALUE='foo` + "\n"},
+		{value: `foo"`, expect: `VALUE=foo"` + "\n"},
+		{value: `"foo'`, expect: `VALUE="foo'` + "\n"},
+		{value: `"`, expect: `VALUE='"'` + "\n"},
+		{value: `'`, expect: `VALUE='''` + "\n"},
+		{value: `a=b # c`, expect: "VALUE=a=b # c\n"},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("[%d]", i+1), func(t *testing.T) {
+			cfg := &config{Value: tc.value}
+			var w bytes.Buffer
+			err := Write(&w, cfg)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expect, w.String())
+			loaded := &config{}
+			err = Load(loaded, NewEnvFileReader(&w, nil))
+			require.NoError(t, err)
+			assert.Equal(t, cfg, loaded)
+		})
+	}
+}
+
+func TestWrite_DotenvFormatter_RoundTrip(t *testing.T) {
+	type config struct {
+		Greeting string
+		Quoted   string
+	}
+	cfg := &config{Greeting: "hello world", Quoted: `"foo"`}
+	var w bytes.Buffer
+	err := Write(&w, cfg, NewDotenvFormatter())
+	require.NoError(t, err)
+	loaded := &config{}
+	err = Load(loaded, NewEnvFileReader(&w, nil))
+	require.NoError(t, err)
+	assert.Equal(t, cfg, loaded)
+}
+
+func TestFormatterHelpers(t *testing.T) {
+	assert.Equal(t, `a\=b\:c\ d\#e\!f`, propertiesEscaped("a=b:c d#e!f", true))
+	assert.Equal(t, `\ a=b:c d#e!f\t\\`, propertiesEscaped(" a=b:c d#e!f\t\\", false))
+	assert.Equal(t, `\ud83d\ude00`, propertiesEscaped("😀", false))
+	assert.Equal(t, `"on"`, yamlKey("on"))
+	assert.Equal(t, `"A B"`, yamlKey("A B"))
+	assert.Equal(t, `"0A"`, yamlKey("0A"))
+	assert.Equal(t, `APP.HOST`, yamlKey("APP.HOST"))
+	assert.Equal(t, `''`, shellQuoted(""))
+	assert.Equal(t, ``, dotenvQuoted(""))
+	assert.Equal(t, `"a\\b\r"`, dotenvQuoted("a\\b\r"))
+	assert.Equal(t, "\"a\\`b\\`\"", systemdQuoted("a`b`"))
+}
+
+func TestWrite_MultipleFormatters(t *testing.T) {
+	var w bytes.Buffer
+	err := Write(&w, &testFormatterConfig{}, NewJsonFormatter(), NewYamlFormatter())
+	require.Error(t, err)
+	assert.Equal(t, "multiple formatters", err.Error())
+}
@@ -0,0 +1,79 @@
+package cfgenv
+
+import "reflect"
+
+// Defaulter is an interface that config structs (including nested and embedded structs) can implement
+//
+// If implemented, SetDefaults is called before any of the struct's fields are loaded - values set by SetDefaults
+// are retained where the env var is missing (and a non-zero value set satisfies a required field)
+//
+// SetDefaults is not called on unexported embedded structs (unless promoted to the containing struct)
+type Defaulter interface {
+	// SetDefaults sets the default values of the struct fields
+	SetDefaults()
+}
+
+// Validator is an interface that config structs (including nested and embedded structs) can implement
+//
+// If implemented, Validate is called once the struct's fields have been loaded (without errors) - any
+// error returned is wrapped in a StructValidationError
+type Validator interface {
+	// Validate validates the loaded struct
+	Validate() error
+}
+
+var defaulterType = reflect.TypeOf((*Defaulter)(nil)).Elem()
+var validatorType = reflect.TypeOf((*Validator)(nil)).Elem()
+
+type structHooks struct {
+	defaulter bool
+	validator bool
+}
+
+// implementedHooks determines which of Defaulter and Validator the struct implements (where they can be called - i.e.
+// the struct is addressable and not an unexported embedded struct)
+func implementedHooks(v reflect.Value) structHooks {
+	if !canCallHooks(v) {
+		return structHooks{}
+	}
+	pt := reflect.PointerTo(v.Type())
+	return structHooks{
+		defaulter: pt.Implements(defaulterType),
+		validator: pt.Implements(validatorType),
+	}
+}
+
+func canCallHooks(v reflect.Value) bool {
+	return v.CanAddr() && v.Addr().CanInterface()
+}
+
+func (h structHooks) or(other structHooks) structHooks {
+	return structHooks{
+		defaulter: h.defaulter || other.defaulter,
+		validator: h.validator || other.validator,
+	}
+}
+
+func callSetDefaults(v reflect.Value) {
+	if !canCallHooks(v) {
+		return
+	} else if d, ok := v.Addr().Interface().(Defaulter); ok {
+		d.SetDefaults()
+	}
+}
+
+func callValidate(v reflect.Value, prefix string, path string) error {
+	if !canCallHooks(v) {
+		return nil
+	} else if vd, ok := v.Addr().Interface().(Validator); ok {
+		if err := vd.Validate(); err != nil {
+			return &StructValidationError{
+				Prefix: prefix,
+				Field:  path,
+				Type:   v.Type(),
+				Err:    err,
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package cfgenv
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type hooksDbConfig struct {
+	Host string `env:"optional"`
+	Port int    `env:"optional"`
+}
+
+func (c *hooksDbConfig) SetDefaults() {
+	c.Host = "localhost"
+	c.Port = 3306
+}
+
+func (c *hooksDbConfig) Validate() error {
+	if c.Port < 1024 {
+		return errors.New("port must be at least 1024")
+	}
+	return nil
+}
+
+type hooksBase struct {
+	Name string `env:"optional"`
+}
+
+func (b hooksBase) Validate() error {
+	if b.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+type hooksConfig struct {
+	hooksBase
+	Database hooksDbConfig  `env:"prefix=DB"`
+	Replica  *hooksDbConfig `env:"prefix=REPLICA"`
+}
+
+func TestLoad_DefaulterAndValidator(t *testing.T) {
+	cfg := &hooksConfig{}
+	err := Load(cfg, MapEnvReader{"NAME": "foo", "DB_PORT": "3307"})
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", cfg.Database.Host)
+	assert.Equal(t, 3307, cfg.Database.Port)
+	assert.Equal(t, "localhost", cfg.Replica.Host)
+	assert.Equal(t, 3306, cfg.Replica.Port)
+
+	cfg = &hooksConfig{}
+	err = Load(cfg, MapEnvReader{"DB_PORT": "80", "REPLICA_PORT": "81"})
+	require.Error(t, err)
+	assert.Equal(t, `'Database' (prefix 'DB') failed validation - port must be at least 1024
+'Replica' (prefix 'REPLICA') failed validation - port must be at least 1024`, err.Error())
+	var sve *StructValidationError
+	require.True(t, errors.As(err, &sve))
+	assert.Equal(t, "DB", sve.Prefix)
+	assert.Equal(t, "Database", sve.Field)
+	assert.Equal(t, "port must be at least 1024", sve.Unwrap().Error())
+
+	cfg = &hooksConfig{}
+	err = Load(cfg, MapEnvReader{})
+	require.Error(t, err)
+	assert.Equal(t, `'cfgenv.hooksConfig' failed validation - name is required`, err.Error())
+}
+
+type HooksAmbiguous1 struct {
+	Foo string `env:"optional"`
+}
+
+func (h *HooksAmbiguous1) SetDefaults() {
+	h.Foo = "foo"
+}
+
+func (h *HooksAmbiguous1) Validate() error {
+	return nil
+}
+
+type HooksAmbiguous2 struct {
+	Bar string `env:"optional"`
+}
+
+func (h *HooksAmbiguous2) SetDefaults() {
+	h.Bar = "bar"
+}
+
+func (h *HooksAmbiguous2) Validate() error {
+	if h.Bar == "" {
+		return errors.New("bar is empty")
+	}
+	return nil
+}
+
+func TestLoad_DefaulterAndValidator_AmbiguousEmbedded(t *testing.T) {
+	type config struct {
+		HooksAmbiguous1
+		HooksAmbiguous2
+	}
+	cfg := &config{}
+	err := Load(cfg, MapEnvReader{}, NewPrefix("APP"))
+	require.NoError(t, err)
+	assert.Equal(t, "foo", cfg.Foo)
+	assert.Equal(t, "bar", cfg.Bar)
+
+	cfg = &config{}
+	err = Load(cfg, MapEnvReader{"APP_BAR": ""}, NewPrefix("APP"))
+	require.Error(t, err)
+	assert.Equal(t, `'cfgenv.HooksAmbiguous2' (prefix 'APP') failed validation - bar is empty`, err.Error())
+}
+
+func TestLoad_DefaulterAndValidator_UnexportedEmbedded(t *testing.T) {
+	type unexported1 = HooksAmbiguous1
+	type config struct {
+		hooksDbConfig
+		unexported1
+	}
+	// hooks are not called on unexported embedded structs (unless promoted)...
+	cfg := &config{}
+	err := Load(cfg, MapEnvReader{"PORT": "80"})
+	require.NoError(t, err)
+	assert.Equal(t, "", cfg.Host)
+	assert.Equal(t, 80, cfg.Port)
+	assert.Equal(t, "", cfg.Foo)
+}
+
+type hooksRequiredConfig struct {
+	Host    string
+	Port    int
+	Name    string
+	Timeout int `env:"optional"`
+}
+
+func (c *hooksRequiredConfig) SetDefaults() {
+	c.Host = "localhost"
+	c.Port = 3306
+}
+
+func TestLoad_Defaulter_Required(t *testing.T) {
+	cfg := &hooksRequiredConfig{}
+	err := Load(cfg, MapEnvReader{"NAME": "foo", "PORT": "3307"})
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", cfg.Host)
+	assert.Equal(t, 3307, cfg.Port)
+	assert.Equal(t, "foo", cfg.Name)
+
+	// fields not defaulted (zero value) are still required...
+	cfg = &hooksRequiredConfig{}
+	err = Load(cfg, MapEnvReader{})
+	require.Error(t, err)
+	assert.Equal(t, "missing env var 'NAME'", err.Error())
+
+	type config struct {
+		Db hooksRequiredConfig `env:"prefix=DB"`
+	}
+	err = Load(&config{}, MapEnvReader{})
+	require.Error(t, err)
+	assert.Equal(t, "missing env var 'DB_NAME'", err.Error())
+	err = Load(&config{}, MapEnvReader{"DB_NAME": "foo"})
+	require.NoError(t, err)
+}
@@ -0,0 +1,160 @@
+package cfgenv
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// IndexGapRule is an option that can be passed to Load or LoadAs
+// and determines how gaps in the indices of indexed env vars are handled
+//
+// Indexed env vars are used for slices of structs (e.g. UPSTREAMS_0_HOST, UPSTREAMS_1_HOST) and, where the env var itself
+// is not present, for other slices (e.g. HOSTS_0, HOSTS_1)
+//
+// The rule can also be set on individual fields using the tag `env:"gaps=compact|stop|error"`
+type IndexGapRule int
+
+const (
+	// IndexGapCompact loads all found indices in order - ignoring any gaps (the default)
+	IndexGapCompact IndexGapRule = iota
+	// IndexGapStop loads indices starting at 0 - stopping at the first gap
+	IndexGapStop
+	// IndexGapError loads indices starting at 0 - and any gap is an error
+	IndexGapError
+)
+
+var indexGapRuleNames = map[string]IndexGapRule{
+	"compact": IndexGapCompact,
+	"stop":    IndexGapStop,
+	"error":   IndexGapError,
+}
+
+func (o *opts) gapRule(fi *fieldInfo) IndexGapRule {
+	if fi.hasGapRule {
+		return fi.gapRule
+	}
+	return o.indexGapRule
+}
+
+// isIndexableSlice determines whether a slice type can be loaded from indexed env vars
+func isIndexableSlice(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 && !isTextType(t)
+}
+
+// structSlicePrefix returns the prefix for the indexed env vars of a slice of structs - the env var name of the field,
+// unless the field has a prefix tag (e.g. `env:"prefix=UPSTREAM"`)
+func structSlicePrefix(name string, prefix string, fi *fieldInfo, options *opts) string {
+	if fi.prefix != "" {
+		return addPrefixes(prefix, fi.prefix, options.separator.GetSeparator())
+	}
+	return name
+}
+
+// findIndices finds the indices of env vars named with the prefix, e.g. prefix "HOSTS" finds indices of HOSTS_0, HOSTS_1 etc.
+//
+// if isStruct, the env var names must also have further parts, e.g. UPSTREAMS_0_HOST
+func findIndices(prefix string, isStruct bool, fi *fieldInfo, options *opts) ([]int, error) {
+	indices := make([]int, 0)
+	for _, seg := range envNameSegments(prefix, isStruct, options) {
+		if idx, err := strconv.Atoi(seg); err == nil && idx >= 0 && strconv.Itoa(idx) == seg {
+			indices = append(indices, idx)
+		}
+	}
+	sort.Ints(indices)
+	switch options.gapRule(fi) {
+	case IndexGapStop:
+		for i, idx := range indices {
+			if idx != i {
+				return indices[:i], nil
+			}
+		}
+	case IndexGapError:
+		for i, idx := range indices {
+			if idx != i {
+				return nil, &ParseError{Name: prefix, msg: fmt.Sprintf("has missing index %d (found index %d)", i, idx)}
+			}
+		}
+	}
+	return indices, nil
+}
+
+// envNameSegments returns the distinct (sorted) name segments that follow the prefix in env var names, e.g. prefix "DB" and
+// env var DB_PRIMARY_HOST returns segment "PRIMARY"
+//
+// if nested, only segments followed by further parts are returned (e.g. DB_PRIMARY_HOST but not DB_PRIMARY)
+func envNameSegments(prefix string, nested bool, options *opts) []string {
+	sep := options.separator.GetSeparator()
+	pfx := prefix + sep
+	found := map[string]bool{}
+	for _, e := range options.reader.Environ() {
+		if name := strings.SplitN(e, "=", 2)[0]; strings.HasPrefix(name, pfx) {
+			seg := name[len(pfx):]
+			if nested {
+				if i := strings.Index(seg, sep); i > 0 {
+					seg = seg[:i]
+				} else {
+					continue
+				}
+			}
+			if seg != "" {
+				found[seg] = true
+			}
+		}
+	}
+	result := make([]string, 0, len(found))
+	for seg := range found {
+		result = append(result, seg)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// setStructSlice sets a slice of structs (or pointers to structs) - where each item is loaded from indexed env vars
+func setStructSlice(fv reflect.Value, prefix string, path string, fi *fieldInfo, options *opts) (bool, error) {
+	indices, err := findIndices(prefix, true, fi, options)
+	if err != nil {
+		return false, err
+	}
+	sep := options.separator.GetSeparator()
+	errs := &LoadErrors{}
+	sl := reflect.MakeSlice(fv.Type(), len(indices), len(indices))
+	for i, idx := range indices {
+		iv := sl.Index(i)
+		if iv.Kind() == reflect.Pointer {
+			iv.Set(reflect.New(iv.Type().Elem()))
+			iv = iv.Elem()
+		}
+		err = loadStruct(iv, prefix+sep+strconv.Itoa(idx), fmt.Sprintf("%s[%d]", path, i), options)
+		if err = options.collect(errs, err); err != nil {
+			return true, err
+		}
+	}
+	fv.Set(sl)
+	return len(indices) > 0, errs.errorOrNil()
+}
+
+// setIndexedSlice sets a slice from indexed env vars, e.g. HOSTS_0, HOSTS_1 - returns false if no indexed env vars were found
+func setIndexedSlice(name string, fld reflect.StructField, fi *fieldInfo, fv reflect.Value, options *opts) (bool, error) {
+	indices, err := findIndices(name, false, fi, options)
+	if err != nil || len(indices) == 0 {
+		return false, err
+	}
+	sep := options.separator.GetSeparator()
+	sl := reflect.MakeSlice(fv.Type(), len(indices), len(indices))
+	for i, idx := range indices {
+		itemName := name + sep + strconv.Itoa(idx)
+		raw, _ := options.reader.LookupEnv(itemName)
+		options.consumeItem(name, itemName, fi)
+		if raw, err = options.decode(itemName, raw, fld, fi); err != nil {
+			return true, err
+		}
+		if err = setValue(itemName, raw, fld, fi, sl.Index(i)); err != nil {
+			return true, err
+		}
+	}
+	fv.Set(sl)
+	return true, nil
+}
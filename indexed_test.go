@@ -0,0 +1,236 @@
+package cfgenv
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type testUpstream struct {
+	Host string
+	Port int `env:"optional,default=80"`
+}
+
+func TestLoad_StructSlices(t *testing.T) {
+	type config struct {
+		Upstreams []testUpstream
+		Backends  []*testUpstream `env:"prefix=BE"`
+		Others    []testUpstream
+	}
+	cfg := &config{}
+	err := Load(cfg, MapEnvReader{
+		"UPSTREAMS_0_HOST": "a.example.com",
+		"UPSTREAMS_1_HOST": "b.example.com",
+		"UPSTREAMS_1_PORT": "8080",
+		"BE_0_HOST":        "c.example.com",
+		"BE_0_PORT":        "443",
+	})
+	require.NoError(t, err)
+	require.Len(t, cfg.Upstreams, 2)
+	assert.Equal(t, testUpstream{Host: "a.example.com", Port: 80}, cfg.Upstreams[0])
+	assert.Equal(t, testUpstream{Host: "b.example.com", Port: 8080}, cfg.Upstreams[1])
+	require.Len(t, cfg.Backends, 1)
+	assert.Equal(t, testUpstream{Host: "c.example.com", Port: 443}, *cfg.Backends[0])
+	assert.Empty(t, cfg.Others)
+}
+
+func TestLoad_StructSlices_WithPrefixOption(t *testing.T) {
+	type config struct {
+		Upstreams []testUpstream
+	}
+	cfg := &config{}
+	err := Load(cfg, NewPrefix("MY"), MapEnvReader{
+		"MY_UPSTREAMS_0_HOST": "a.example.com",
+	})
+	require.NoError(t, err)
+	require.Len(t, cfg.Upstreams, 1)
+	assert.Equal(t, "a.example.com", cfg.Upstreams[0].Host)
+}
+
+func TestLoad_StructSlices_Errors(t *testing.T) {
+	type config struct {
+		Upstreams []testUpstream
+	}
+	cfg := &config{}
+	err := Load(cfg, MapEnvReader{
+		"UPSTREAMS_0_HOST": "a.example.com",
+		"UPSTREAMS_0_PORT": "x",
+		"UPSTREAMS_1_PORT": "81",
+	})
+	require.Error(t, err)
+	errs := err.(*LoadErrors).Errors
+	require.Len(t, errs, 2)
+	assert.Equal(t, "env var 'UPSTREAMS_0_PORT' is not an int", errs[0].Error())
+	assert.Equal(t, "Upstreams[0].Port", errs[0].(*ParseError).Field)
+	assert.Equal(t, "missing env var 'UPSTREAMS_1_HOST'", errs[1].Error())
+	assert.Equal(t, "Upstreams[1].Host", errs[1].(*MissingVarError).Field)
+}
+
+func TestLoad_StructSlices_Validation(t *testing.T) {
+	type config struct {
+		Upstreams []testUpstream `env:"notempty"`
+	}
+	err := Load(&config{}, MapEnvReader{})
+	require.Error(t, err)
+	assert.Equal(t, "env var 'UPSTREAMS' failed validation 'notempty' - value is empty", err.Error())
+}
+
+func TestLoad_IndexedSlices(t *testing.T) {
+	type config struct {
+		Hosts  []string
+		Ports  []int `env:"optional"`
+		Others []string
+	}
+	cfg := &config{}
+	err := Load(cfg, MapEnvReader{
+		"HOSTS_0":  "a",
+		"HOSTS_1":  "b",
+		"HOSTS_10": "c",
+		"PORTS_0":  "80",
+		"PORTS_01": "81",
+		"OTHERS":   "x,y",
+		"OTHERS_0": "z",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, cfg.Hosts)
+	assert.Equal(t, []int{80}, cfg.Ports)
+	assert.Equal(t, []string{"x", "y"}, cfg.Others)
+}
+
+func TestLoad_IndexedSlices_Errors(t *testing.T) {
+	type config struct {
+		Ports []int
+	}
+	err := Load(&config{}, MapEnvReader{
+		"PORTS_0": "80",
+		"PORTS_1": "x",
+	})
+	require.Error(t, err)
+	assert.Equal(t, "env var 'PORTS_1' is not an int", err.Error())
+	assert.Equal(t, "Ports", err.(*LoadErrors).Errors[0].(*ParseError).Field)
+}
+
+func TestLoad_IndexGapRules(t *testing.T) {
+	env := MapEnvReader{
+		"HOSTS_0":          "a",
+		"HOSTS_2":          "c",
+		"UPSTREAMS_1_HOST": "b",
+	}
+	type config struct {
+		Hosts     []string `env:"optional"`
+		Upstreams []testUpstream
+	}
+	testCases := []struct {
+		options           []any
+		expectHosts       []string
+		expectUpstreams   int
+		expectErrorsCount int
+	}{
+		{
+			options:         []any{env},
+			expectHosts:     []string{"a", "c"},
+			expectUpstreams: 1,
+		},
+		{
+			options:         []any{env, IndexGapCompact},
+			expectHosts:     []string{"a", "c"},
+			expectUpstreams: 1,
+		},
+		{
+			options:         []any{env, IndexGapStop},
+			expectHosts:     []string{"a"},
+			expectUpstreams: 0,
+		},
+		{
+			options:           []any{env, IndexGapError},
+			expectErrorsCount: 2,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("[%d]", i+1), func(t *testing.T) {
+			cfg := &config{}
+			err := Load(cfg, tc.options...)
+			if tc.expectErrorsCount > 0 {
+				require.Error(t, err)
+				assert.Len(t, err.(*LoadErrors).Errors, tc.expectErrorsCount)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectHosts, cfg.Hosts)
+				assert.Len(t, cfg.Upstreams, tc.expectUpstreams)
+			}
+		})
+	}
+}
+
+func TestLoad_IndexGapRules_Tag(t *testing.T) {
+	type config struct {
+		Hosts []string `env:"gaps=error"`
+	}
+	err := Load(&config{}, MapEnvReader{
+		"HOSTS_0": "a",
+		"HOSTS_2": "c",
+	})
+	require.Error(t, err)
+	assert.Equal(t, "env var 'HOSTS' has missing index 1 (found index 2)", err.Error())
+
+	err = Load(&config{}, MapEnvReader{"HOSTS_0": "a"}, IndexGapStop, IndexGapError)
+	require.Error(t, err)
+	assert.Equal(t, "multiple index gap rule options", err.Error())
+}
+
+func TestLoad_IndexGapRules_TagErrors(t *testing.T) {
+	type badRule struct {
+		Hosts []string `env:"gaps=foo"`
+	}
+	err := Load(&badRule{}, MapEnvReader{})
+	require.Error(t, err)
+	assert.Equal(t, "env tag 'gaps' on field 'Hosts' - invalid rule 'foo' (must be compact, stop or error)", err.Error())
+
+	type notSlice struct {
+		Host string `env:"gaps=stop"`
+	}
+	err = Load(&notSlice{}, MapEnvReader{})
+	require.Error(t, err)
+	assert.Equal(t, "cannot use env tag 'gaps' on field 'Host' (only for slices)", err.Error())
+}
+
+func TestWrite_StructSlices(t *testing.T) {
+	type config struct {
+		Upstreams []*testUpstream `env:"prefix=UP"`
+	}
+	cfg := &config{
+		Upstreams: []*testUpstream{
+			{Host: "a.example.com", Port: 80},
+			{Host: "b.example.com", Port: 8080},
+		},
+	}
+	var w bytes.Buffer
+	err := Write(&w, cfg)
+	require.NoError(t, err)
+	const expect = `UP_0_HOST=a.example.com
+UP_0_PORT=80
+UP_1_HOST=b.example.com
+UP_1_PORT=8080
+`
+	assert.Equal(t, expect, w.String())
+
+	reloaded := &config{}
+	err = Load(reloaded, NewEnvFileReader(bytes.NewReader(w.Bytes()), nil))
+	require.NoError(t, err)
+	assert.Equal(t, cfg, reloaded)
+}
+
+func TestExample_StructSlices(t *testing.T) {
+	type config struct {
+		Upstreams []testUpstream
+	}
+	var w bytes.Buffer
+	err := Example(&w, &config{})
+	require.NoError(t, err)
+	const expect = `UPSTREAMS_0_HOST=<string>
+UPSTREAMS_0_PORT=80
+`
+	assert.Equal(t, expect, w.String())
+}
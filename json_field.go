@@ -0,0 +1,37 @@
+package cfgenv
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// setJsonValue sets a field with the `json` env tag - by unmarshalling the (expanded & decoded) env var value
+func setJsonValue(fv reflect.Value, fld reflect.StructField, fi *fieldInfo, name string, prefix string, path string, options *opts) (bool, error) {
+	raw, ok, err := options.lookupEnv(name, prefix, path, fld, fi)
+	if err != nil {
+		return ok, err
+	} else if !ok && !fi.optional {
+		return false, &MissingVarError{Name: name, Type: fld.Type}
+	} else if !ok && fi.hasDefault {
+		raw = fi.defaultValue
+	} else if !ok {
+		return false, nil
+	}
+	if ok {
+		if raw, err = options.decode(name, raw, fld, fi); err != nil {
+			return true, err
+		}
+	}
+	pv := reflect.New(fv.Type())
+	if err = json.Unmarshal([]byte(raw), pv.Interface()); err != nil {
+		return ok, &ParseError{Name: name, Type: fld.Type, Err: err, msg: "is not valid JSON - " + err.Error()}
+	}
+	fv.Set(pv.Elem())
+	return ok, fi.validate(name, fv)
+}
+
+// jsonValueString returns the compact JSON of a field with the `json` env tag
+func jsonValueString(fv reflect.Value) (string, error) {
+	data, err := json.Marshal(fv.Interface())
+	return string(data), err
+}
@@ -0,0 +1,136 @@
+package cfgenv
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+type testRoute struct {
+	Path    string   `json:"path"`
+	Targets []string `json:"targets"`
+}
+
+type testJsonLevel int
+
+func (l *testJsonLevel) UnmarshalJSON(data []byte) error {
+	switch strings.Trim(string(data), `"`) {
+	case "low":
+		*l = 1
+	case "high":
+		*l = 2
+	default:
+		return errors.New("unknown level")
+	}
+	return nil
+}
+
+func TestLoad_JsonFields(t *testing.T) {
+	type config struct {
+		Routes   []testRoute          `env:"json"`
+		RouteMap map[string]testRoute `env:"json"`
+		Route    *testRoute           `env:"json"`
+		Missing  *testRoute           `env:"json"`
+		Rules    map[string]any       `env:"json,encoding=base64"`
+		Level    testJsonLevel        `env:"json"`
+		Numbers  []int                `env:"json,optional,default='[1,2]'"`
+		Expanded []string             `env:"json,expand"`
+		Channel  chan int             `env:"json,optional"`
+	}
+	cfg := &config{}
+	err := Load(cfg, MapEnvReader{
+		"ROUTES":    `[{"path":"/a","targets":["x","y"]}]`,
+		"ROUTE_MAP": `{"b":{"path":"/b"}}`,
+		"ROUTE":     `{"path":"/c"}`,
+		"RULES":     "eyJhIjp0cnVlfQ==",
+		"LEVEL":     `"high"`,
+		"EXPANDED":  `["${HOST}"]`,
+		"HOST":      "localhost",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []testRoute{{Path: "/a", Targets: []string{"x", "y"}}}, cfg.Routes)
+	assert.Equal(t, map[string]testRoute{"b": {Path: "/b"}}, cfg.RouteMap)
+	assert.Equal(t, &testRoute{Path: "/c"}, cfg.Route)
+	assert.Nil(t, cfg.Missing)
+	assert.Equal(t, map[string]any{"a": true}, cfg.Rules)
+	assert.Equal(t, testJsonLevel(2), cfg.Level)
+	assert.Equal(t, []int{1, 2}, cfg.Numbers)
+	assert.Equal(t, []string{"localhost"}, cfg.Expanded)
+	assert.Nil(t, cfg.Channel)
+}
+
+func TestLoad_JsonFields_Errors(t *testing.T) {
+	type config struct {
+		Routes []testRoute    `env:"json"`
+		Level  testJsonLevel  `env:"json"`
+		Rules  map[string]any `env:"json,minlen=1"`
+		Route  testRoute      `env:"json,prefix=X"`
+	}
+	err := Load(&config{}, MapEnvReader{
+		"ROUTES": `[{"path":`,
+		"LEVEL":  `"medium"`,
+		"RULES":  `{}`,
+	})
+	require.Error(t, err)
+	errs := err.(*LoadErrors).Errors
+	require.Len(t, errs, 4)
+	assert.Equal(t, "env var 'ROUTES' is not valid JSON - unexpected end of JSON input", errs[0].Error())
+	assert.Equal(t, "env var 'LEVEL' is not valid JSON - unknown level", errs[1].Error())
+	assert.Equal(t, "env var 'RULES' failed validation 'minlen=1' - item count 0 is less than 1", errs[2].Error())
+	assert.Equal(t, "cannot use env tag 'prefix' on field 'Route' (only for structs, slices of structs or maps)", errs[3].Error())
+	var pe *ParseError
+	require.True(t, errors.As(errs[0], &pe))
+	assert.Equal(t, "Routes", pe.Field)
+	var se *json.SyntaxError
+	assert.True(t, errors.As(errs[0], &se))
+
+	err = Load(&config{}, MapEnvReader{})
+	require.Error(t, err)
+	assert.Equal(t, "missing env var 'ROUTES'", err.(*LoadErrors).Errors[0].Error())
+}
+
+func TestWrite_JsonFields(t *testing.T) {
+	type config struct {
+		Routes []testRoute          `env:"json"`
+		Route  *testRoute           `env:"json"`
+		Nil    *testRoute           `env:"json"`
+		Names  []string             `env:"json"`
+		Name   string               `env:"json"`
+		Map    map[string]testRoute `env:"json,optional,default={}"`
+	}
+	cfg := &config{
+		Routes: []testRoute{{Path: "/a", Targets: []string{"x"}}},
+		Route:  &testRoute{Path: "/b"},
+		Names:  []string{"a,b"},
+		Name:   "foo",
+	}
+	var w bytes.Buffer
+	err := Write(&w, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, `ROUTES=[{"path":"/a","targets":["x"]}]
+ROUTE={"path":"/b","targets":null}
+NAMES=["a,b"]
+NAME='"foo"'
+MAP=null
+`, w.String())
+
+	loaded := &config{}
+	err = Load(loaded, NewEnvFileReader(&w, nil))
+	require.NoError(t, err)
+	assert.Equal(t, cfg, loaded)
+
+	w.Reset()
+	err = Example(&w, &config{})
+	require.NoError(t, err)
+	assert.Equal(t, `ROUTES=null
+ROUTE={"path":"","targets":null}
+NIL={"path":"","targets":null}
+NAMES=null
+NAME=""
+MAP={}
+`, w.String())
+}
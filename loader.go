@@ -4,22 +4,24 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
-	"regexp"
 	"strconv"
-	"strings"
-	"time"
 )
 
 // LoadAs loads the specified T config struct type from environment vars
 //
 // the type of T must be a struct
 //
+// if the config is loaded but env vars cannot be unset (see UnsetError), the config is returned as well as the error
+//
 // Use any options (such as PrefixOption, SeparatorOption, NamingOption, EnvReader, Decoder or multiple CustomSetterOption) to alter
 // loading behaviour
 func LoadAs[T any](options ...any) (*T, error) {
 	var cfg T
 	if err := Load(&cfg, options...); err == nil {
 		return &cfg, nil
+	} else if _, ok := err.(*UnsetError); ok {
+		// the config was loaded...
+		return &cfg, err
 	} else {
 		return nil, err
 	}
@@ -31,6 +33,8 @@ func LoadAs[T any](options ...any) (*T, error) {
 //
 // Use any options (such as PrefixOption, SeparatorOption, NamingOption, EnvReader, Decoder or multiple CustomSetterOption) to alter
 // loading behaviour
+//
+// All fields are attempted and any errors are returned as *LoadErrors (use FailFastOption to stop at the first error)
 func Load(cfg any, options ...any) error {
 	o, err := buildOpts(options...)
 	if err != nil {
@@ -45,7 +49,11 @@ func Load(cfg any, options ...any) error {
 			return errors.New("cfg not a struct")
 		}
 	}
-	return loadStruct(v, o.prefix.GetPrefix(), o)
+	o.consumed = &[]string{}
+	if err = loadStruct(v, o.prefix.GetPrefix(), "", o); err == nil {
+		err = o.unsetConsumed()
+	}
+	return err
 }
 
 func buildOpts(options ...any) (*opts, error) {
@@ -59,13 +67,28 @@ func buildOpts(options ...any) (*opts, error) {
 			encodingRawBase64:    NewRawBase64Decoder(),
 			encodingRawBase64Url: NewRawBase64UrlDecoder(),
 		},
-		reader: defaultReader,
+		reader:    defaultReader,
+		formatter: defaultFormatter,
 	}
 	pfx := false
 	sep := false
 	name := false
 	expand := false
 	reader := false
+	failFast := false
+	gapRule := false
+	quoted := false
+	format := false
+	extended := false
+	optional := false
+	strict := false
+	deprecated := false
+	fileVars := false
+	secretNames := false
+	redactor := false
+	reveal := false
+	unset := false
+	formatter := false
 	for _, o := range options {
 		if o != nil {
 			switch ot := o.(type) {
@@ -99,6 +122,97 @@ func buildOpts(options ...any) (*opts, error) {
 				}
 				result.reader = ot
 				reader = true
+			case FailFastOption:
+				if failFast {
+					return nil, errors.New("multiple fail fast options")
+				}
+				result.failFast = ot.FailFast()
+				failFast = true
+			case IndexGapRule:
+				if gapRule {
+					return nil, errors.New("multiple index gap rule options")
+				}
+				result.indexGapRule = ot
+				gapRule = true
+			case CollectionFormat:
+				if format {
+					return nil, errors.New("multiple collection format options")
+				}
+				result.collectionFormat = ot
+				format = true
+			case ExtendedDurationsOption:
+				if extended {
+					return nil, errors.New("multiple extended durations options")
+				}
+				result.extendedDurations = ot.ExtendedDurations()
+				extended = true
+			case QuotedOption:
+				if quoted {
+					return nil, errors.New("multiple quoted options")
+				}
+				result.quoted = ot.Quoted()
+				quoted = true
+			case OptionalByDefaultOption:
+				if optional {
+					return nil, errors.New("multiple optional by default options")
+				}
+				result.optionalByDefault = ot.OptionalByDefault()
+				optional = true
+			case StrictAliasesOption:
+				if strict {
+					return nil, errors.New("multiple strict aliases options")
+				}
+				result.strictAliases = ot.StrictAliases()
+				strict = true
+			case DeprecatedNameHandler:
+				if deprecated {
+					return nil, errors.New("multiple deprecated name handlers")
+				}
+				result.deprecatedNameHandler = ot
+				deprecated = true
+			case FileVarsOption:
+				if fileVars {
+					return nil, errors.New("multiple file vars options")
+				}
+				result.fileVars = ot
+				fileVars = true
+			case SecretNamesOption:
+				if secretNames {
+					return nil, errors.New("multiple secret names options")
+				}
+				result.secretNames = ot
+				secretNames = true
+			case Redactor:
+				if redactor {
+					return nil, errors.New("multiple redactors")
+				}
+				result.redactor = ot
+				redactor = true
+			case RevealSecretsOption:
+				if reveal {
+					return nil, errors.New("multiple reveal secrets options")
+				}
+				result.revealSecrets = ot.RevealSecrets()
+				reveal = true
+			case UnsetSecretsOption:
+				if unset {
+					return nil, errors.New("multiple unset secrets options")
+				}
+				result.unsetSecrets = ot.UnsetSecrets()
+				unset = true
+			case Formatter:
+				if formatter {
+					return nil, errors.New("multiple formatters")
+				}
+				result.formatter = ot
+				formatter = true
+			case EnumOption:
+				if result.enums == nil {
+					result.enums = map[reflect.Type]EnumOption{}
+				} else if result.enums[ot.EnumType()] != nil {
+					return nil, fmt.Errorf("multiple enum options for type %s", ot.EnumType().String())
+				}
+				result.enums[ot.EnumType()] = ot
 			case CustomSetterOption:
 				result.customs = append(result.customs, ot)
 			case Decoder:
@@ -112,13 +226,42 @@ func buildOpts(options ...any) (*opts, error) {
 }
 
 type opts struct {
-	prefix    PrefixOption
-	separator SeparatorOption
-	naming    NamingOption
-	expander  ExpandOption
-	customs   []CustomSetterOption
-	decoders  map[string]Decoder
-	reader    EnvReader
+	prefix                PrefixOption
+	separator             SeparatorOption
+	naming                NamingOption
+	expander              ExpandOption
+	customs               []CustomSetterOption
+	decoders              map[string]Decoder
+	reader                EnvReader
+	failFast              bool
+	indexGapRule          IndexGapRule
+	quoted                bool
+	collectionFormat      CollectionFormat
+	extendedDurations     bool
+	enums                 map[reflect.Type]EnumOption
+	optionalByDefault     bool
+	strictAliases         bool
+	deprecatedNameHandler DeprecatedNameHandler
+	fileVars              FileVarsOption
+	secretNames           SecretNamesOption
+	redactor              Redactor
+	revealSecrets         bool
+	unsetSecrets          bool
+	consumed              *[]string
+	formatter             Formatter
+}
+
+// inherit returns the options for the nested fields of a struct field (or embedded struct) - where the field has the
+// `optional` or `required` env tag, the nested fields are optional or required by default (pointer and gopt.Optional
+// fields remain optional unless they have their own `required` tag)
+func (o *opts) inherit(fld reflect.StructField) *opts {
+	optional, required := hasTagToken(fld, tokenOptional), hasTagToken(fld, tokenRequired)
+	if !optional && !required {
+		return o
+	}
+	result := *o
+	result.optionalByDefault = optional && !required
+	return &result
 }
 
 func (o *opts) expand(s string, fi *fieldInfo) string {
@@ -130,101 +273,234 @@ func (o *opts) expand(s string, fi *fieldInfo) string {
 	return s
 }
 
-func loadStruct(v reflect.Value, prefix string, options *opts) error {
+// decode expands and decodes (if the field has an encoding) a raw env var value
+func (o *opts) decode(name string, raw string, fld reflect.StructField, fi *fieldInfo) (string, error) {
+	raw = o.expand(raw, fi)
+	if fi.decoder != nil {
+		decoded, err := fi.decoder.Decode(raw)
+		if err != nil {
+			return "", &DecodeError{Name: name, Type: fld.Type, Encoding: fi.decoder.Encoding(), Err: err}
+		}
+		return decoded, nil
+	}
+	return raw, nil
+}
+
+func loadStruct(v reflect.Value, prefix string, path string, options *opts) error {
+	errs := &LoadErrors{}
+	loaded := make([]*loadedField, 0, v.NumField())
+	callSetDefaults(v)
+	if err := loadStructFields(v, prefix, path, options, implementedHooks(v), &loaded, errs); err != nil {
+		return err
+	}
+	for _, err := range checkConstraints(loaded, prefix, options) {
+		if err = options.collect(errs, err); err != nil {
+			return err
+		}
+	}
+	if len(errs.Errors) == 0 {
+		errs.add(callValidate(v, prefix, path))
+	}
+	return errs.errorOrNil()
+}
+
+// loadedField records a field loaded by loadStruct (used for checking cross-field constraints)
+type loadedField struct {
+	name    string
+	path    string
+	fld     reflect.StructField
+	fi      *fieldInfo
+	present bool
+}
+
+// loadStructFields loads the fields of a struct (and its embedded structs)
+//
+// hooks denotes which of Defaulter and Validator the containing struct implements - if implemented by the containing struct
+// they are not called for embedded structs (as the methods are either promoted or overridden)
+func loadStructFields(v reflect.Value, prefix string, path string, options *opts, hooks structHooks, loaded *[]*loadedField, errs *LoadErrors) error {
 	t := v.Type()
 	for f := 0; f < t.NumField(); f++ {
-		if fld := t.Field(f); fld.Anonymous {
+		var err error
+		if fld := t.Field(f); isIgnoredField(fld) {
+			continue
+		} else if fld.Anonymous {
 			ev := v.Field(f)
-			if err := loadStruct(ev, prefix, options); err != nil {
-				return err
+			if !hooks.defaulter {
+				callSetDefaults(ev)
+			}
+			errCount := len(errs.Errors)
+			err = loadStructFields(ev, prefix, path, options.inherit(fld), hooks.or(implementedHooks(ev)), loaded, errs)
+			if err == nil && !hooks.validator && len(errs.Errors) == errCount {
+				err = callValidate(ev, prefix, path)
 			}
 		} else if fld.IsExported() {
-			fi, err := getFieldInfo(fld, options)
-			if err != nil {
-				return err
+			var lf *loadedField
+			if lf, err = loadField(v, f, fld, prefix, joinPath(path, fld.Name), options, hooks.defaulter); lf != nil {
+				*loaded = append(*loaded, lf)
 			}
-			name := options.naming.BuildName(prefix, options.separator.GetSeparator(), fld, fi.name)
-			switch {
-			case fi.optionalSetter != nil:
-				if raw, ok := options.reader.LookupEnv(name); ok {
-					raw = options.expand(raw, fi)
-					if fi.decoder != nil {
-						if raw, err = fi.decoder.Decode(raw); err != nil {
-							return fmt.Errorf("unable to decode env var '%s' (encoding: '%s'): %s", name, fi.decoder.Encoding(), err.Error())
-						}
-					}
-					if err = fi.optionalSetter(v.Field(f), raw, true); err != nil {
-						return err
-					}
-				} else if fi.hasDefault {
-					if err = fi.optionalSetter(v.Field(f), fi.defaultValue, false); err != nil {
-						return err
-					}
-				}
-			case fi.customSetter != nil:
-				raw, ok := options.reader.LookupEnv(name)
-				if !ok && !fi.optional {
-					return fmt.Errorf("missing env var '%s'", name)
-				} else if !ok && fi.hasDefault {
-					raw = fi.defaultValue
-				}
-				if ok {
-					raw = options.expand(raw, fi)
-					if fi.decoder != nil {
-						if raw, err = fi.decoder.Decode(raw); err != nil {
-							return fmt.Errorf("unable to decode env var '%s' (encoding: '%s'): %s", name, fi.decoder.Encoding(), err.Error())
-						}
-					}
-				}
-				if err = fi.customSetter.Set(fld, v.Field(f), raw, ok); err != nil {
-					return err
-				}
-			case fi.isMatchedMap && fi.isPrefixedMap:
-				pfx := addPrefixes(prefix, fi.prefix, options.separator.GetSeparator())
-				setPrefixMatchMap(v.Field(f), fi.matchRegex, pfx, fi, options)
-			case fi.isMatchedMap:
-				setMatchMap(v.Field(f), fi.matchRegex, fi, options)
-			case fi.isPrefixedMap:
-				pfx := addPrefixes(prefix, fi.prefix, options.separator.GetSeparator())
-				setPrefixMap(v.Field(f), pfx, fi, options)
-			case fi.isStruct:
-				fv := v.Field(f)
-				if fi.pointer {
-					fvp := reflect.New(fv.Type().Elem())
-					fv.Set(fvp)
-					fv = fvp.Elem()
-				}
-				pfx := addPrefixes(prefix, fi.prefix, options.separator.GetSeparator())
-				if err = loadStruct(fv, pfx, options); err != nil {
-					return err
-				}
-			default:
-				raw, ok := options.reader.LookupEnv(name)
-				if !ok && !fi.optional {
-					return fmt.Errorf("missing env var '%s'", name)
-				} else if !ok && fi.hasDefault {
-					raw = fi.defaultValue
-				} else if !ok && fi.pointer {
-					continue
-				}
-				if ok {
-					raw = options.expand(raw, fi)
-					if fi.decoder != nil {
-						if raw, err = fi.decoder.Decode(raw); err != nil {
-							return fmt.Errorf("unable to decode env var '%s' (encoding: '%s'): %s", name, fi.decoder.Encoding(), err.Error())
-						}
-					}
-				}
-				if err = setValue(name, raw, fld, fi, v.Field(f)); err != nil {
-					return err
+		}
+		if err = options.collect(errs, err); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collect adds the error to errs - unless fail fast, in which case the error is returned
+func (o *opts) collect(errs *LoadErrors, err error) error {
+	if err != nil && o.failFast {
+		return err
+	}
+	errs.add(err)
+	return nil
+}
+
+// loadField loads a struct field - where defaulted denotes that SetDefaults (see Defaulter) has been called on the struct
+func loadField(v reflect.Value, f int, fld reflect.StructField, prefix string, path string, options *opts, defaulted bool) (*loadedField, error) {
+	fld, fv, wrapped := unwrapSecret(fld, v.Field(f))
+	fi, err := getFieldInfo(fld, options)
+	if err == nil && wrapped {
+		err = fi.wrapSecret(fld)
+	}
+	if err != nil {
+		return nil, withFieldPath(err, path, options.naming.BuildName(prefix, options.separator.GetSeparator(), fld, tagName(fld)))
+	}
+	lf := &loadedField{
+		name: options.naming.BuildName(prefix, options.separator.GetSeparator(), fld, fi.name),
+		path: path,
+		fld:  fld,
+		fi:   fi,
+	}
+	lf.present, err = loadFieldValue(fv, fld, fi, lf.name, prefix, path, options)
+	if _, missing := err.(*MissingVarError); missing && defaulted && !fv.IsZero() {
+		// the value set by SetDefaults satisfies the required field...
+		err = nil
+	}
+	if err != nil && options.isSecret(lf.name, fi) {
+		err = redactError(err, lf.name, fld.Type)
+	}
+	return lf, withFieldPath(err, path, lf.name)
+}
+
+func loadFieldValue(fv reflect.Value, fld reflect.StructField, fi *fieldInfo, name string, prefix string, path string, options *opts) (present bool, err error) {
+	switch {
+	case fi.isJson:
+		return setJsonValue(fv, fld, fi, name, prefix, path, options)
+	case fi.optionalSetter != nil:
+		var raw string
+		var ok bool
+		if raw, ok, err = options.lookupEnv(name, prefix, path, fld, fi); err != nil {
+			return ok, err
+		} else if ok {
+			if raw, err = options.decode(name, raw, fld, fi); err != nil {
+				return true, err
+			}
+			if err = fi.optionalSetter(fv, raw, true); err != nil {
+				return true, &ParseError{Name: name, Type: fld.Type, Err: err}
+			}
+			return true, fi.validate(name, fv)
+		} else if fi.hasDefault {
+			if err = fi.optionalSetter(fv, fi.defaultValue, false); err != nil {
+				return false, &ParseError{Name: name, Type: fld.Type, Err: err}
+			}
+			return false, fi.validate(name, fv)
+		}
+	case fi.customSetter != nil:
+		raw, ok, lerr := options.lookupEnv(name, prefix, path, fld, fi)
+		if lerr != nil {
+			return ok, lerr
+		} else if !ok && !fi.optional {
+			return false, &MissingVarError{Name: name, Type: fld.Type}
+		} else if !ok && fi.hasDefault {
+			raw = fi.defaultValue
+		}
+		if ok {
+			if raw, err = options.decode(name, raw, fld, fi); err != nil {
+				return true, err
+			}
+		}
+		if err = fi.customSetter.Set(fld, fv, raw, ok); err == nil && (ok || fi.hasDefault) {
+			err = fi.validate(name, fv)
+		}
+		return ok, err
+	case fi.isMatchedMap || fi.isPrefixedMap:
+		pfx := addPrefixes(prefix, fi.prefix, options.separator.GetSeparator())
+		if err = setEnvMap(fv, pfx, fi.isPrefixedMap, fld, fi, options); err == nil {
+			err = fi.validate(name, fv)
+		}
+		return fv.Len() > 0, err
+	case fi.isStruct:
+		if fi.pointer {
+			fvp := reflect.New(fv.Type().Elem())
+			fv.Set(fvp)
+			fv = fvp.Elem()
+		}
+		pfx := addPrefixes(prefix, fi.prefix, options.separator.GetSeparator())
+		return false, loadStruct(fv, pfx, path, options.inherit(fld))
+	case fi.isStructMap:
+		pfx := addPrefixes(prefix, fi.prefix, options.separator.GetSeparator())
+		if present, err = setStructMap(fv, pfx, path, options.inherit(fld)); err == nil {
+			err = fi.validate(name, fv)
+		}
+		return present, err
+	case fi.isStructSlice:
+		pfx := structSlicePrefix(name, prefix, fi, options)
+		if present, err = setStructSlice(fv, pfx, path, fi, options.inherit(fld)); err == nil {
+			err = fi.validate(name, fv)
+		}
+		return present, err
+	default:
+		raw, ok, lerr := options.lookupEnv(name, prefix, path, fld, fi)
+		if lerr != nil {
+			return ok, lerr
+		} else if !ok && isIndexableSlice(fld.Type) {
+			if present, err = setIndexedSlice(name, fld, fi, fv, options); present || err != nil {
+				if err == nil {
+					err = fi.validate(name, fv)
 				}
+				return present, err
+			}
+		}
+		if !ok && !fi.optional {
+			return false, &MissingVarError{Name: name, Type: fld.Type}
+		} else if !ok && fi.hasDefault {
+			raw = fi.defaultValue
+		} else if !ok {
+			return false, nil
+		}
+		if ok {
+			if raw, err = options.decode(name, raw, fld, fi); err != nil {
+				return true, err
 			}
 		}
+		if err = setValue(name, raw, fld, fi, fv); err == nil {
+			err = fi.validate(name, fv)
+		}
+		return ok, err
 	}
-	return nil
+	return false, nil
+}
+
+func setValue(name string, raw string, fld reflect.StructField, fi *fieldInfo, fv reflect.Value) error {
+	return setValueAt(name, raw, fld, fi, fv, 0)
 }
 
-func setValue(name string, raw string, fld reflect.StructField, fi *fieldInfo, fv reflect.Value) (err error) {
+// setValueAt sets a value - where level is the collection nesting level (determining the delimiter used for slices and maps)
+func setValueAt(name string, raw string, fld reflect.StructField, fi *fieldInfo, fv reflect.Value, level int) (err error) {
+	if enum := fi.enumOf(fv.Type()); enum != nil {
+		return setEnumValue(name, raw, fv, enum)
+	} else if isTextType(fv.Type()) {
+		return setTextValue(name, raw, fv)
+	} else if fv.Kind() == reflect.Pointer && !fi.pointer {
+		// pointer collection item...
+		pv := reflect.New(fv.Type().Elem())
+		if err = setValueAt(name, raw, fld, fi, pv.Elem(), level); err == nil {
+			fv.Set(pv)
+		}
+		return err
+	} else if level == 0 && fi.format != CollectionFormatDelimited && isCollectionType(fv.Type()) {
+		return setFormatted(name, raw, fld, fi, fv)
+	}
 	k := fv.Type().Kind()
 	if fi.pointer {
 		k = fv.Type().Elem().Kind()
@@ -235,119 +511,169 @@ func setValue(name string, raw string, fld reflect.StructField, fi *fieldInfo, f
 	case reflect.Bool:
 		err = setBoolValue(name, raw, fv, fi.pointer)
 	case reflect.Int:
-		err = setIntValue[int](name, raw, fv, fi.pointer)
+		err = setIntValue[int](name, raw, fv, fi.pointer, fi.unit)
 	case reflect.Int8:
-		err = setIntValue[int8](name, raw, fv, fi.pointer)
+		err = setIntValue[int8](name, raw, fv, fi.pointer, fi.unit)
 	case reflect.Int16:
-		err = setIntValue[int16](name, raw, fv, fi.pointer)
+		err = setIntValue[int16](name, raw, fv, fi.pointer, fi.unit)
 	case reflect.Int32:
-		err = setIntValue[int32](name, raw, fv, fi.pointer)
+		err = setIntValue[int32](name, raw, fv, fi.pointer, fi.unit)
 	case reflect.Int64:
-		if fv.Type() == durationType {
-			err = setIntValue[time.Duration](name, raw, fv, fi.pointer)
+		if fv.Type() == durationType || (fi.pointer && fv.Type().Elem() == durationType) {
+			err = setDurationValue(name, raw, fv, fi.pointer, fi.extendedDurations)
 		} else {
-			err = setIntValue[int64](name, raw, fv, fi.pointer)
+			err = setIntValue[int64](name, raw, fv, fi.pointer, fi.unit)
 		}
 	case reflect.Uint:
-		err = setUintValue[uint](name, raw, fv, fi.pointer)
+		err = setUintValue[uint](name, raw, fv, fi.pointer, fi.unit)
 	case reflect.Uint8:
-		err = setUintValue[uint8](name, raw, fv, fi.pointer)
+		err = setUintValue[uint8](name, raw, fv, fi.pointer, fi.unit)
 	case reflect.Uint16:
-		err = setUintValue[uint16](name, raw, fv, fi.pointer)
+		err = setUintValue[uint16](name, raw, fv, fi.pointer, fi.unit)
 	case reflect.Uint32:
-		err = setUintValue[uint32](name, raw, fv, fi.pointer)
+		err = setUintValue[uint32](name, raw, fv, fi.pointer, fi.unit)
 	case reflect.Uint64:
-		err = setUintValue[uint64](name, raw, fv, fi.pointer)
+		err = setUintValue[uint64](name, raw, fv, fi.pointer, fi.unit)
 	case reflect.Float32:
-		err = setFloatValue[float32](name, raw, fv, fi.pointer)
+		err = setFloatValue[float32](name, raw, fv, fi.pointer, fi.unit)
 	case reflect.Float64:
-		err = setFloatValue[float64](name, raw, fv, fi.pointer)
+		err = setFloatValue[float64](name, raw, fv, fi.pointer, fi.unit)
+	case reflect.Complex64:
+		err = setComplexValue[complex64](name, raw, fv, fi.pointer)
+	case reflect.Complex128:
+		err = setComplexValue[complex128](name, raw, fv, fi.pointer)
+	case reflect.Array:
+		err = setArray(name, raw, fld, fi, fv, level)
 	case reflect.Slice:
-		err = setSlice(name, raw, fld, fi, fv)
+		err = setSlice(name, raw, fld, fi, fv, level)
 	case reflect.Map:
-		err = setMap(name, raw, fld, fi, fv)
+		err = setMap(name, raw, fld, fi, fv, level)
 	}
 	return
 }
 
-func setSlice(name string, raw string, fld reflect.StructField, fi *fieldInfo, fv reflect.Value) error {
+func setSlice(name string, raw string, fld reflect.StructField, fi *fieldInfo, fv reflect.Value, level int) error {
 	if raw != "" {
-		if fld.Type.Elem().Kind() == reflect.Uint8 {
+		if fv.Type().Elem().Kind() == reflect.Uint8 {
 			sl := []byte(raw)
 			fv.Set(reflect.ValueOf(sl))
 			return nil
 		}
-		vs := strings.Split(raw, fi.delimiter)
-		sl := reflect.MakeSlice(fv.Type(), len(vs), len(vs))
-		for i, v := range vs {
-			if err := setValue(name, v, fld, fi, sl.Index(i)); err != nil {
-				return err
-			}
+		vs, err := fi.splitItems(raw, fi.delimiterAt(level), -1)
+		if err != nil {
+			return &ParseError{Name: name, Type: fv.Type(), Err: err, msg: "contains " + err.Error()}
 		}
-		fv.Set(sl)
+		return setSliceItems(name, vs, fld, fi, fv, level)
 	}
 	return nil
 }
 
-func setMap(name string, raw string, fld reflect.StructField, fi *fieldInfo, fv reflect.Value) error {
+// setArray sets a fixed-length array from delimited items (the item count must match the array length) - or, for byte
+// arrays, from the (decoded) bytes of the value (the byte count must match the array length)
+func setArray(name string, raw string, fld reflect.StructField, fi *fieldInfo, fv reflect.Value, level int) error {
+	av := reflect.New(fv.Type()).Elem()
+	if fv.Type().Elem().Kind() == reflect.Uint8 {
+		if len(raw) != av.Len() {
+			return &ParseError{Name: name, Type: fv.Type(), msg: fmt.Sprintf("has %d bytes (expected %d)", len(raw), av.Len())}
+		}
+		reflect.Copy(av, reflect.ValueOf([]byte(raw)))
+		fv.Set(av)
+		return nil
+	}
+	vs := make([]string, 0)
 	if raw != "" {
-		vs := strings.Split(raw, fi.delimiter)
-		m := reflect.MakeMap(fv.Type())
-		kt := fv.Type().Key()
-		vt := fv.Type().Elem()
-		for _, v := range vs {
-			kvp := strings.Split(v, fi.separator)
-			if len(kvp) != 2 {
-				return fmt.Errorf("env var '%s' contains invalid key/value pair - %s", name, v)
-			}
-			kv := reflect.New(kt).Elem()
-			if err := setValue(name, kvp[0], fld, fi, kv); err != nil {
-				return err
-			}
-			vv := reflect.New(vt).Elem()
-			if err := setValue(name, kvp[1], fld, fi, vv); err != nil {
-				return err
-			}
-			m.SetMapIndex(kv, vv)
+		var err error
+		if vs, err = fi.splitItems(raw, fi.delimiterAt(level), -1); err != nil {
+			return &ParseError{Name: name, Type: fv.Type(), Err: err, msg: "contains " + err.Error()}
+		}
+	}
+	if len(vs) != av.Len() {
+		return &ParseError{Name: name, Type: fv.Type(), msg: fmt.Sprintf("has %d items (expected %d)", len(vs), av.Len())}
+	}
+	for i, v := range vs {
+		if err := setValueAt(name, fi.itemRaw(v, av.Index(i)), fld, fi, av.Index(i), level+1); err != nil {
+			return err
+		}
+	}
+	fv.Set(av)
+	return nil
+}
+
+// setSliceItems sets a slice from already split items
+func setSliceItems(name string, vs []string, fld reflect.StructField, fi *fieldInfo, fv reflect.Value, level int) error {
+	sl := reflect.MakeSlice(fv.Type(), len(vs), len(vs))
+	for i, v := range vs {
+		if err := setValueAt(name, fi.itemRaw(v, sl.Index(i)), fld, fi, sl.Index(i), level+1); err != nil {
+			return err
 		}
-		fv.Set(m)
 	}
+	fv.Set(sl)
 	return nil
 }
 
-func setPrefixMap(fv reflect.Value, prefix string, fi *fieldInfo, options *opts) {
-	m := map[string]string{}
-	for _, e := range options.reader.Environ() {
-		if strings.HasPrefix(e, prefix) {
-			ev := strings.SplitN(e, "=", 2)
-			m[ev[0][len(prefix):]] = options.expand(ev[1], fi)
+// setMap sets a map from key/value pairs (e.g. "a:1,b:2") - or, for sets (map[T]struct{} or map[T]bool), from a plain
+// list of keys (e.g. "a,b")
+func setMap(name string, raw string, fld reflect.StructField, fi *fieldInfo, fv reflect.Value, level int) error {
+	if raw != "" {
+		vs, err := fi.splitItems(raw, fi.delimiterAt(level), -1)
+		if err != nil {
+			return &ParseError{Name: name, Type: fv.Type(), Err: err, msg: "contains " + err.Error()}
 		}
+		return setMapItems(name, vs, fld, fi, fv, level)
 	}
-	fv.Set(reflect.ValueOf(m))
+	return nil
 }
 
-func setPrefixMatchMap(fv reflect.Value, rx *regexp.Regexp, prefix string, fi *fieldInfo, options *opts) {
-	m := map[string]string{}
-	for _, e := range options.reader.Environ() {
-		if strings.HasPrefix(e, prefix) {
-			ev := strings.SplitN(e, "=", 2)
-			if name := ev[0][len(prefix):]; rx.MatchString(name) {
-				m[name] = options.expand(ev[1], fi)
+// setMapItems sets a map from already split items (each a key/value pair or, for sets, a key)
+func setMapItems(name string, vs []string, fld reflect.StructField, fi *fieldInfo, fv reflect.Value, level int) (err error) {
+	m := reflect.MakeMap(fv.Type())
+	kt := fv.Type().Key()
+	vt := fv.Type().Elem()
+	isSet := isSetItemType(vt)
+	for _, v := range vs {
+		kv := reflect.New(kt).Elem()
+		vv := reflect.New(vt).Elem()
+		n := -1
+		if isSet || vt.Kind() == reflect.Bool || isCollectionType(vt) {
+			n = 2
+		}
+		kvp, _ := fi.splitItems(v, fi.separator, n)
+		if isSet || (vt.Kind() == reflect.Bool && len(kvp) == 1) {
+			if err = setValueAt(name, fi.unquoteItem(v), fld, fi, kv, level+1); err != nil {
+				return err
+			}
+			if vt.Kind() == reflect.Bool {
+				vv.SetBool(true)
 			}
+			m.SetMapIndex(kv, vv)
+			continue
+		}
+		if len(kvp) != 2 {
+			return &ParseError{Name: name, Type: fv.Type(), msg: fmt.Sprintf("contains invalid key/value pair - %s", v)}
+		}
+		if err = setValueAt(name, fi.unquoteItem(kvp[0]), fld, fi, kv, level+1); err != nil {
+			return err
+		}
+		if err = setItemValue(name, fi.itemRaw(kvp[1], vv), fld, fi, vv, level+1); err != nil {
+			return err
 		}
+		m.SetMapIndex(kv, vv)
 	}
-	fv.Set(reflect.ValueOf(m))
+	fv.Set(m)
+	return nil
 }
 
-func setMatchMap(fv reflect.Value, rx *regexp.Regexp, fi *fieldInfo, options *opts) {
-	m := map[string]string{}
-	for _, e := range options.reader.Environ() {
-		ev := strings.SplitN(e, "=", 2)
-		if rx.MatchString(ev[0]) {
-			m[ev[0]] = options.expand(ev[1], fi)
+// setItemValue sets a collection item value - using the item custom setter (if any)
+func setItemValue(name string, raw string, fld reflect.StructField, fi *fieldInfo, fv reflect.Value, level int) error {
+	if fi.itemSetter != nil {
+		itemFld := fld
+		itemFld.Type = fv.Type()
+		if err := fi.itemSetter.Set(itemFld, fv, raw, true); err != nil {
+			return &ParseError{Name: name, Type: fv.Type(), Err: err}
 		}
+		return nil
 	}
-	fv.Set(reflect.ValueOf(m))
+	return setValueAt(name, raw, fld, fi, fv, level)
 }
 
 func setStringValue(raw string, fv reflect.Value, isPtr bool) {
@@ -367,12 +693,12 @@ func setBoolValue(name string, raw string, fv reflect.Value, isPtr bool) error {
 		}
 		return nil
 	} else {
-		return fmt.Errorf("env var '%s' is not a bool", name)
+		return &ParseError{Name: name, Type: fv.Type(), Err: bErr, msg: "is not a bool"}
 	}
 }
 
-func setIntValue[T int | int8 | int16 | int32 | int64 | time.Duration](name string, raw string, fv reflect.Value, isPtr bool) error {
-	if i, err := strconv.ParseInt(raw, 0, getBitSize(fv, isPtr)); err == nil {
+func setIntValue[T int | int8 | int16 | int32 | int64](name string, raw string, fv reflect.Value, isPtr bool, unit numericUnit) error {
+	if i, err := unit.parseInt(raw, getBitSize(fv, isPtr)); err == nil {
 		if isPtr {
 			pv := T(i)
 			fv.Set(reflect.ValueOf(&pv))
@@ -381,12 +707,12 @@ func setIntValue[T int | int8 | int16 | int32 | int64 | time.Duration](name stri
 		}
 		return nil
 	} else {
-		return fmt.Errorf("env var '%s' is not an int", name)
+		return unit.parseError(name, fv, err, "is not an int")
 	}
 }
 
-func setUintValue[T uint | uint8 | uint16 | uint32 | uint64](name string, raw string, fv reflect.Value, isPtr bool) error {
-	if i, err := strconv.ParseUint(raw, 0, getBitSize(fv, isPtr)); err == nil {
+func setUintValue[T uint | uint8 | uint16 | uint32 | uint64](name string, raw string, fv reflect.Value, isPtr bool, unit numericUnit) error {
+	if i, err := unit.parseUint(raw, getBitSize(fv, isPtr)); err == nil {
 		if isPtr {
 			pv := T(i)
 			fv.Set(reflect.ValueOf(&pv))
@@ -395,12 +721,12 @@ func setUintValue[T uint | uint8 | uint16 | uint32 | uint64](name string, raw st
 		}
 		return nil
 	} else {
-		return fmt.Errorf("env var '%s' is not a uint", name)
+		return unit.parseError(name, fv, err, "is not a uint")
 	}
 }
 
-func setFloatValue[T float32 | float64](name string, raw string, fv reflect.Value, isPtr bool) error {
-	if f, err := strconv.ParseFloat(raw, getBitSize(fv, isPtr)); err == nil {
+func setFloatValue[T float32 | float64](name string, raw string, fv reflect.Value, isPtr bool, unit numericUnit) error {
+	if f, err := unit.parseFloat(raw, getBitSize(fv, isPtr)); err == nil {
 		if isPtr {
 			pv := T(f)
 			fv.Set(reflect.ValueOf(&pv))
@@ -409,7 +735,21 @@ func setFloatValue[T float32 | float64](name string, raw string, fv reflect.Valu
 		}
 		return nil
 	} else {
-		return fmt.Errorf("env var '%s' is not a float", name)
+		return unit.parseError(name, fv, err, "is not a float")
+	}
+}
+
+func setComplexValue[T complex64 | complex128](name string, raw string, fv reflect.Value, isPtr bool) error {
+	if c, err := strconv.ParseComplex(raw, getBitSize(fv, isPtr)); err == nil {
+		if isPtr {
+			pv := T(c)
+			fv.Set(reflect.ValueOf(&pv))
+		} else {
+			fv.Set(reflect.ValueOf(T(c)))
+		}
+		return nil
+	} else {
+		return &ParseError{Name: name, Type: fv.Type(), Err: err, msg: "is not a complex number"}
 	}
 }
 
@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/go-andiamo/gopt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"net/http"
 	"os"
 	"reflect"
 	"strings"
@@ -349,14 +351,14 @@ func TestLoad(t *testing.T) {
 			env: map[string]string{
 				"TEST": "foo",
 			},
-			expectError: "env var 'TEST' is not an int",
+			expectError: "env var 'TEST' is not a duration",
 		},
 		{
 			cfg: &struct {
 				Test time.Duration
 			}{},
 			env: map[string]string{
-				"TEST": "1",
+				"TEST": "1ns",
 			},
 			expect: `{"Test":1}`,
 		},
@@ -687,13 +689,13 @@ func TestLoad(t *testing.T) {
 		},
 		{
 			cfg: &struct {
-				Test []struct{ Foo string }
+				Test []func()
 			}{},
 			expectError: "field 'Test' has unsupported slice item type",
 		},
 		{
 			cfg: &struct {
-				Test []*struct{ Foo string }
+				Test []*chan int
 			}{},
 			expectError: "field 'Test' has unsupported slice item type",
 		},
@@ -701,13 +703,13 @@ func TestLoad(t *testing.T) {
 			cfg: &struct {
 				Test map[string]struct{ Foo string }
 			}{},
-			expectError: "field 'Test' has unsupported map item type",
+			expectError: "field 'Test' has unsupported map item type (use env tag 'prefix' for maps of structs)",
 		},
 		{
 			cfg: &struct {
 				Test map[string]*struct{ Foo string }
 			}{},
-			expectError: "field 'Test' has unsupported map item type",
+			expectError: "field 'Test' has unsupported map item type (use env tag 'prefix' for maps of structs)",
 		},
 		{
 			cfg: &struct {
@@ -749,7 +751,7 @@ func TestLoad(t *testing.T) {
 			cfg: &struct {
 				Test string `env:"prefix=STUFF_"`
 			}{},
-			expectError: "cannot use env tag 'prefix' on field 'Test' (only for structs or map[string]string)",
+			expectError: "cannot use env tag 'prefix' on field 'Test' (only for structs, slices of structs or maps)",
 		},
 		{
 			cfg: &struct {
@@ -797,7 +799,7 @@ func TestLoad(t *testing.T) {
 			cfg: &struct {
 				Test string `env:"match=''"`
 			}{},
-			expectError: "cannot use env tag 'match' on field 'Test' (only for map[string]string)",
+			expectError: "cannot use env tag 'match' on field 'Test' (only for maps - other than maps of structs)",
 		},
 		{
 			cfg: &struct {
@@ -1225,13 +1227,13 @@ func TestLoadFile(t *testing.T) {
 				Test time.Duration
 			}{},
 			env:         `TEST=foo`,
-			expectError: "env var 'TEST' is not an int",
+			expectError: "env var 'TEST' is not a duration",
 		},
 		{
 			cfg: &struct {
 				Test time.Duration
 			}{},
-			env:    `TEST=1`,
+			env:    `TEST=1ns`,
 			expect: `{"Test":1}`,
 		},
 		{
@@ -1503,13 +1505,13 @@ func TestLoadFile(t *testing.T) {
 		},
 		{
 			cfg: &struct {
-				Test []struct{ Foo string }
+				Test []func()
 			}{},
 			expectError: "field 'Test' has unsupported slice item type",
 		},
 		{
 			cfg: &struct {
-				Test []*struct{ Foo string }
+				Test []*chan int
 			}{},
 			expectError: "field 'Test' has unsupported slice item type",
 		},
@@ -1517,13 +1519,13 @@ func TestLoadFile(t *testing.T) {
 			cfg: &struct {
 				Test map[string]struct{ Foo string }
 			}{},
-			expectError: "field 'Test' has unsupported map item type",
+			expectError: "field 'Test' has unsupported map item type (use env tag 'prefix' for maps of structs)",
 		},
 		{
 			cfg: &struct {
 				Test map[string]*struct{ Foo string }
 			}{},
-			expectError: "field 'Test' has unsupported map item type",
+			expectError: "field 'Test' has unsupported map item type (use env tag 'prefix' for maps of structs)",
 		},
 		{
 			cfg: &struct {
@@ -1559,7 +1561,7 @@ func TestLoadFile(t *testing.T) {
 			cfg: &struct {
 				Test string `env:"prefix=STUFF_"`
 			}{},
-			expectError: "cannot use env tag 'prefix' on field 'Test' (only for structs or map[string]string)",
+			expectError: "cannot use env tag 'prefix' on field 'Test' (only for structs, slices of structs or maps)",
 		},
 		{
 			cfg: &struct {
@@ -1647,3 +1649,333 @@ func TestLoad_Embedded(t *testing.T) {
 	err = Load(cfg, MapEnvReader{})
 	require.Error(t, err)
 }
+
+func TestLoad_CollectsErrors(t *testing.T) {
+	type inner struct {
+		Host string
+		Port int
+	}
+	type config struct {
+		inner
+		Name  string
+		Count int
+		Sub   inner  `env:"prefix=SUB"`
+		Bad   string `env:"unknown=foo"`
+	}
+	cfg := &config{}
+	err := Load(cfg, MapEnvReader{"PORT": "x", "COUNT": "1", "SUB_HOST": "foo"})
+	require.Error(t, err)
+	var le *LoadErrors
+	require.True(t, errors.As(err, &le))
+	assert.Len(t, le.Errors, 5)
+	assert.Equal(t, `missing env var 'HOST'
+env var 'PORT' is not an int
+missing env var 'NAME'
+missing env var 'SUB_PORT'
+invalid tag 'unknown=foo' on field 'Bad'`, err.Error())
+	assert.Equal(t, 1, cfg.Count)
+	assert.Equal(t, "foo", cfg.Sub.Host)
+}
+
+func TestLoad_FailFast(t *testing.T) {
+	type config struct {
+		Name  string
+		Count int
+	}
+	cfg := &config{}
+	err := Load(cfg, MapEnvReader{"COUNT": "x"}, NewFailFast())
+	require.Error(t, err)
+	var le *LoadErrors
+	assert.False(t, errors.As(err, &le))
+	assert.Equal(t, "missing env var 'NAME'", err.Error())
+
+	err = Load(cfg, NewFailFast(), NewFailFast())
+	require.Error(t, err)
+	assert.Equal(t, "multiple fail fast options", err.Error())
+}
+
+func TestLoad_Complex(t *testing.T) {
+	type config struct {
+		C64      complex64
+		C128     complex128
+		Ptr      *complex128
+		Items    []complex64
+		Optional gopt.Optional[complex128]
+	}
+	cfg := &config{}
+	err := Load(cfg, MapEnvReader{
+		"C64":      "1+2i",
+		"C128":     "(-1.5-0.5i)",
+		"PTR":      "3i",
+		"ITEMS":    "1,2i,3+3i",
+		"OPTIONAL": "2",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, complex64(1+2i), cfg.C64)
+	assert.Equal(t, -1.5-0.5i, cfg.C128)
+	assert.Equal(t, 3i, *cfg.Ptr)
+	assert.Equal(t, []complex64{1, 2i, 3 + 3i}, cfg.Items)
+	assert.Equal(t, complex128(2), cfg.Optional.Default(0))
+
+	err = Load(cfg, MapEnvReader{"C64": "x", "C128": "1", "ITEMS": "1"})
+	require.Error(t, err)
+	assert.Equal(t, "env var 'C64' is not a complex number", err.Error())
+}
+
+func TestLoad_Arrays(t *testing.T) {
+	type config struct {
+		Point  [3]float64
+		Names  [2]string `env:"delim=;"`
+		Ptrs   [2]*int   `env:"optional"`
+		Key    [4]byte   `env:"encoding=base64"`
+		Raw    [3]byte
+		Quoted [2]string `env:"quoted"`
+		Ranged [2]int    `env:"min=1"`
+		Empty  [0]int    `env:"optional"`
+		Sized  [2]uint32 `env:"unit=bytes"`
+	}
+	cfg := &config{}
+	err := Load(cfg, MapEnvReader{
+		"POINT":  "1.5,2,-3",
+		"NAMES":  "a,b;c",
+		"PTRS":   "1,2",
+		"KEY":    "AQIDBA==",
+		"RAW":    "abc",
+		"QUOTED": `"a,b",c`,
+		"RANGED": "1,2",
+		"SIZED":  "1KiB,2MB",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, [3]float64{1.5, 2, -3}, cfg.Point)
+	assert.Equal(t, [2]string{"a,b", "c"}, cfg.Names)
+	assert.Equal(t, 2, *cfg.Ptrs[1])
+	assert.Equal(t, [4]byte{1, 2, 3, 4}, cfg.Key)
+	assert.Equal(t, [3]byte{'a', 'b', 'c'}, cfg.Raw)
+	assert.Equal(t, [2]string{"a,b", "c"}, cfg.Quoted)
+	assert.Equal(t, [2]uint32{1024, 2_000_000}, cfg.Sized)
+
+	testCases := []struct {
+		env         MapEnvReader
+		expectError string
+	}{
+		{
+			env:         MapEnvReader{"POINT": "1,2"},
+			expectError: "env var 'POINT' has 2 items (expected 3)",
+		},
+		{
+			env:         MapEnvReader{"POINT": "1,2,3,4"},
+			expectError: "env var 'POINT' has 4 items (expected 3)",
+		},
+		{
+			env:         MapEnvReader{"POINT": "1,x,3"},
+			expectError: "env var 'POINT' is not a float",
+		},
+		{
+			env:         MapEnvReader{"KEY": "AQID"},
+			expectError: "env var 'KEY' has 3 bytes (expected 4)",
+		},
+		{
+			env:         MapEnvReader{"RAW": "abcd"},
+			expectError: "env var 'RAW' has 4 bytes (expected 3)",
+		},
+		{
+			env:         MapEnvReader{"RANGED": "0,1"},
+			expectError: "env var 'RANGED' failed validation 'min=1' - value 0 is less than 1",
+		},
+	}
+	valid := MapEnvReader{"POINT": "1,2,3", "NAMES": "a;b", "KEY": "AQIDBA==", "RAW": "abc", "QUOTED": "a,b", "RANGED": "1,1", "SIZED": "1,1"}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("[%d]", i+1), func(t *testing.T) {
+			env := MapEnvReader{}
+			for k, v := range valid {
+				env[k] = v
+			}
+			for k, v := range tc.env {
+				env[k] = v
+			}
+			err := Load(&config{}, env)
+			require.Error(t, err)
+			assert.Equal(t, tc.expectError, err.Error())
+		})
+	}
+
+	err = Load(&struct {
+		Test [2]struct{ Foo string }
+	}{}, MapEnvReader{})
+	require.Error(t, err)
+	assert.Equal(t, "field 'Test' has unsupported array item type", err.Error())
+	err = Load(&struct {
+		Test *[2]int
+	}{}, MapEnvReader{})
+	require.Error(t, err)
+	assert.Equal(t, "field 'Test' has unsupported type - *[2]int", err.Error())
+}
+
+func TestLoad_IgnoredFields(t *testing.T) {
+	type embedded struct {
+		Inner string
+	}
+	type config struct {
+		embedded `env:"-"`
+		Name     string
+		Cache    map[string]any `env:"-"`
+		Client   *http.Client   `env:"-"`
+		Derived  string         `env:"-"`
+		Dash     string         `env:"-,optional"`
+	}
+	cfg := &config{Derived: "keep"}
+	err := Load(cfg, MapEnvReader{"NAME": "foo", "DERIVED": "x", "INNER": "x", "-": "dash"})
+	require.NoError(t, err)
+	assert.Equal(t, "foo", cfg.Name)
+	assert.Equal(t, "keep", cfg.Derived)
+	assert.Equal(t, "", cfg.Inner)
+	assert.Nil(t, cfg.Cache)
+	assert.Nil(t, cfg.Client)
+	assert.Equal(t, "dash", cfg.Dash)
+}
+
+func TestLoad_Required(t *testing.T) {
+	type sub struct {
+		Host string
+		Port *int
+		Name string `env:"required"`
+	}
+	type config struct {
+		Host     string
+		Port     int    `env:"required"`
+		Ptr      *int   `env:"required"`
+		Optional string `env:"optional"`
+		Sub      sub    `env:"prefix=SUB"`
+	}
+	cfg := &config{}
+	err := Load(cfg, MapEnvReader{})
+	require.Error(t, err)
+	assert.Equal(t, `missing env var 'HOST'
+missing env var 'PORT'
+missing env var 'PTR'
+missing env var 'SUB_HOST'
+missing env var 'SUB_NAME'`, err.Error())
+
+	err = Load(cfg, NewOptionalByDefault(), MapEnvReader{"HOST": "x"})
+	require.Error(t, err)
+	assert.Equal(t, `missing env var 'PORT'
+missing env var 'PTR'
+missing env var 'SUB_NAME'`, err.Error())
+
+	err = Load(cfg, NewOptionalByDefault(), MapEnvReader{"PORT": "1", "PTR": "2", "SUB_NAME": "foo"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, cfg.Port)
+	assert.Equal(t, "foo", cfg.Sub.Name)
+
+	err = Load(cfg, NewOptionalByDefault(), NewOptionalByDefault())
+	require.Error(t, err)
+	assert.Equal(t, "multiple optional by default options", err.Error())
+
+	err = Load(&struct {
+		Test string `env:"required,default=x"`
+	}{}, MapEnvReader{})
+	require.Error(t, err)
+	assert.Equal(t, "cannot use env tag 'required' with 'optional', 'default', 'required_if' or 'group' on field 'Test'", err.Error())
+	err = Load(&struct {
+		Test string `env:"optional,required"`
+	}{}, MapEnvReader{})
+	require.Error(t, err)
+	assert.Equal(t, "cannot use env tag 'required' with 'optional', 'default', 'required_if' or 'group' on field 'Test'", err.Error())
+}
+
+func TestLoad_InheritedOptional(t *testing.T) {
+	type item struct {
+		Host string
+		Port int `env:"required"`
+	}
+	type sub struct {
+		Host    string
+		Port    *int
+		Timeout gopt.Optional[int]
+		Nested  struct {
+			Name string
+		}
+	}
+	type embedded struct {
+		Extra string
+	}
+	type config struct {
+		Optional sub             `env:"optional,prefix=OPT"`
+		Required sub             `env:"required,prefix=REQ"`
+		Items    []item          `env:"optional,prefix=ITEMS"`
+		ByName   map[string]item `env:"optional,prefix=BY"`
+		embedded `env:"optional"`
+	}
+	cfg := &config{}
+	err := Load(cfg, NewOptionalByDefault(), MapEnvReader{
+		"ITEMS_0_HOST": "a",
+		"BY_X_HOST":    "b",
+		"REQ_HOST":     "c",
+		"REQ_NAME":     "d",
+	})
+	require.Error(t, err)
+	assert.Equal(t, `missing env var 'ITEMS_0_PORT'
+missing env var 'BY_X_PORT'`, err.Error())
+
+	cfg = &config{}
+	err = Load(cfg, MapEnvReader{
+		"REQ_HOST": "c",
+		"REQ_PORT": "1",
+		"REQ_NAME": "d",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "c", cfg.Required.Host)
+	assert.Equal(t, 1, *cfg.Required.Port)
+	assert.Equal(t, "", cfg.Optional.Host)
+	assert.Equal(t, "", cfg.Extra)
+
+	// pointer and gopt.Optional fields are not made required by an inherited required...
+	cfg = &config{}
+	err = Load(cfg, NewOptionalByDefault(), MapEnvReader{
+		"REQ_HOST": "c",
+		"REQ_NAME": "d",
+	})
+	require.NoError(t, err)
+	assert.Nil(t, cfg.Required.Port)
+	assert.False(t, cfg.Required.Timeout.IsPresent())
+	cfg = &config{}
+	err = Load(cfg, NewOptionalByDefault(), MapEnvReader{
+		"REQ_HOST":    "c",
+		"REQ_NAME":    "d",
+		"REQ_PORT":    "1",
+		"REQ_TIMEOUT": "2",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, *cfg.Required.Port)
+	assert.Equal(t, 2, cfg.Required.Timeout.Default(0))
+}
+
+func TestLoad_TagTokensAreNotNames(t *testing.T) {
+	type config struct {
+		Settings map[string]int `env:"json"`
+		Other    string         `env:"unset"`
+		Json     string         `env:"name=json"`
+		Unset    string         `env:"'unset'"`
+		Upper    string         `env:"JSON"`
+	}
+	cfg := &config{}
+	err := Load(cfg, MapEnvReader{
+		"SETTINGS": `{"a":1}`,
+		"OTHER":    "o",
+		"json":     "j",
+		"unset":    "u",
+		"JSON":     "J",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"a": 1}, cfg.Settings)
+	assert.Equal(t, "o", cfg.Other)
+	assert.Equal(t, "j", cfg.Json)
+	assert.Equal(t, "u", cfg.Unset)
+	assert.Equal(t, "J", cfg.Upper)
+
+	err = Load(&struct {
+		Group string `env:"group"`
+	}{}, MapEnvReader{"group": "g"})
+	require.Error(t, err)
+	assert.Equal(t, "cannot use env tag 'group' without value on field 'Group' (use quotes if necessary)", err.Error())
+}
@@ -4,25 +4,58 @@ import (
 	"github.com/go-andiamo/gopt"
 	"reflect"
 	"strconv"
+	"strings"
 )
 
 type optionalSetterFn func(v reflect.Value, raw string, present bool) error
 
 var optionalTypeSetters = map[reflect.Type]optionalSetterFn{
-	reflect.TypeOf(gopt.Optional[string]{}):  optionalStringSetter,
-	reflect.TypeOf(gopt.Optional[bool]{}):    optionalBoolSetter,
-	reflect.TypeOf(gopt.Optional[float32]{}): optionalFloat32Setter,
-	reflect.TypeOf(gopt.Optional[float64]{}): optionalFloat64Setter,
-	reflect.TypeOf(gopt.Optional[int]{}):     optionalIntSetter,
-	reflect.TypeOf(gopt.Optional[int8]{}):    optionalInt8Setter,
-	reflect.TypeOf(gopt.Optional[int16]{}):   optionalInt16Setter,
-	reflect.TypeOf(gopt.Optional[int32]{}):   optionalInt32Setter,
-	reflect.TypeOf(gopt.Optional[int64]{}):   optionalInt64Setter,
-	reflect.TypeOf(gopt.Optional[uint]{}):    optionalUintSetter,
-	reflect.TypeOf(gopt.Optional[uint8]{}):   optionalUint8Setter,
-	reflect.TypeOf(gopt.Optional[uint16]{}):  optionalUint16Setter,
-	reflect.TypeOf(gopt.Optional[uint32]{}):  optionalUint32Setter,
-	reflect.TypeOf(gopt.Optional[uint64]{}):  optionalUint64Setter,
+	reflect.TypeOf(gopt.Optional[string]{}):     optionalStringSetter,
+	reflect.TypeOf(gopt.Optional[bool]{}):       optionalBoolSetter,
+	reflect.TypeOf(gopt.Optional[float32]{}):    optionalFloat32Setter,
+	reflect.TypeOf(gopt.Optional[float64]{}):    optionalFloat64Setter,
+	reflect.TypeOf(gopt.Optional[complex64]{}):  optionalComplex64Setter,
+	reflect.TypeOf(gopt.Optional[complex128]{}): optionalComplex128Setter,
+	reflect.TypeOf(gopt.Optional[int]{}):        optionalIntSetter,
+	reflect.TypeOf(gopt.Optional[int8]{}):       optionalInt8Setter,
+	reflect.TypeOf(gopt.Optional[int16]{}):      optionalInt16Setter,
+	reflect.TypeOf(gopt.Optional[int32]{}):      optionalInt32Setter,
+	reflect.TypeOf(gopt.Optional[int64]{}):      optionalInt64Setter,
+	reflect.TypeOf(gopt.Optional[uint]{}):       optionalUintSetter,
+	reflect.TypeOf(gopt.Optional[uint8]{}):      optionalUint8Setter,
+	reflect.TypeOf(gopt.Optional[uint16]{}):     optionalUint16Setter,
+	reflect.TypeOf(gopt.Optional[uint32]{}):     optionalUint32Setter,
+	reflect.TypeOf(gopt.Optional[uint64]{}):     optionalUint64Setter,
+	optDurationType:                             optionalDurationSetter(false),
+}
+
+const goptPkgPath = "github.com/go-andiamo/gopt"
+
+func isOptionalType(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.PkgPath() == goptPkgPath && strings.HasPrefix(t.Name(), "Optional[")
+}
+
+// optionalItemType returns the type T of a gopt.Optional[T]
+func optionalItemType(t reflect.Type) reflect.Type {
+	m, _ := reflect.PointerTo(t).MethodByName("GetOk")
+	return m.Type.Out(0)
+}
+
+// reflectOptionalSetter creates an optionalSetterFn for any gopt.Optional[T] - where setItem sets the T value
+func reflectOptionalSetter(setItem func(raw string, iv reflect.Value) error) optionalSetterFn {
+	return func(v reflect.Value, raw string, present bool) error {
+		iv := reflect.New(optionalItemType(v.Type())).Elem()
+		if err := setItem(raw, iv); err != nil {
+			return err
+		}
+		ov := reflect.New(v.Type())
+		ov.MethodByName("OrElseSet").Call([]reflect.Value{iv})
+		if !present {
+			ov.MethodByName("UnSet").Call(nil)
+		}
+		v.Set(ov.Elem())
+		return nil
+	}
 }
 
 func optionalStringSetter(v reflect.Value, raw string, present bool) error {
@@ -81,6 +114,36 @@ func optionalFloat64Setter(v reflect.Value, raw string, present bool) error {
 	return err
 }
 
+func optionalComplex64Setter(v reflect.Value, raw string, present bool) error {
+	var cv complex128
+	var err error
+	if cv, err = strconv.ParseComplex(raw, 64); err == nil {
+		if present {
+			av := gopt.Empty[complex64]().WasSetElseSet(complex64(cv))
+			v.Set(reflect.ValueOf(*av))
+		} else {
+			av := gopt.Of[complex64](complex64(cv))
+			v.Set(reflect.ValueOf(*av))
+		}
+	}
+	return err
+}
+
+func optionalComplex128Setter(v reflect.Value, raw string, present bool) error {
+	var cv complex128
+	var err error
+	if cv, err = strconv.ParseComplex(raw, 128); err == nil {
+		if present {
+			av := gopt.Empty[complex128]().WasSetElseSet(cv)
+			v.Set(reflect.ValueOf(*av))
+		} else {
+			av := gopt.Of[complex128](cv)
+			v.Set(reflect.ValueOf(*av))
+		}
+	}
+	return err
+}
+
 func optionalIntSetter(v reflect.Value, raw string, present bool) error {
 	var iv int
 	var err error
@@ -124,3 +124,64 @@ func (e *expandOpt) expand(s string, er EnvReader) string {
 	}
 	return ""
 }
+
+// FailFastOption is an option that can be passed to Load or LoadAs
+// and determines whether loading stops at the first error encountered
+//
+// By default, loading continues through the whole config struct and all errors are returned as LoadErrors
+type FailFastOption interface {
+	// FailFast returns whether loading should stop at the first error encountered
+	FailFast() bool
+}
+
+type failFastOpt struct{}
+
+func (f *failFastOpt) FailFast() bool {
+	return true
+}
+
+// NewFailFast creates a new FailFastOption - where loading stops at (and returns) the first error encountered
+func NewFailFast() FailFastOption {
+	return &failFastOpt{}
+}
+
+// QuotedOption is an option that can be passed to Load or LoadAs
+// and determines whether slice and map values are split with quote and escape awareness
+//
+// When quoted, delimiters and separators within double quotes (e.g. `"a,b",c`) or escaped with a backslash (e.g. `a\,b,c`)
+// are not split on - fields can also use the `quoted` or `no-quoted` env tags
+type QuotedOption interface {
+	// Quoted returns whether slice and map values are split with quote and escape awareness
+	Quoted() bool
+}
+
+type quotedOpt struct{}
+
+func (q *quotedOpt) Quoted() bool {
+	return true
+}
+
+// NewQuoted creates a new QuotedOption - where slice and map values are split with quote and escape awareness
+func NewQuoted() QuotedOption {
+	return &quotedOpt{}
+}
+
+// OptionalByDefaultOption is an option that can be passed to Load or LoadAs
+// and determines whether fields are optional unless they have the `required` env tag
+//
+// By default, fields are required unless they have the `optional` env tag (or are pointers or gopt.Optional)
+type OptionalByDefaultOption interface {
+	// OptionalByDefault returns whether fields are optional unless they have the `required` env tag
+	OptionalByDefault() bool
+}
+
+type optionalByDefaultOpt struct{}
+
+func (o *optionalByDefaultOpt) OptionalByDefault() bool {
+	return true
+}
+
+// NewOptionalByDefault creates a new OptionalByDefaultOption - where fields are optional unless they have the `required` env tag
+func NewOptionalByDefault() OptionalByDefaultOption {
+	return &optionalByDefaultOpt{}
+}
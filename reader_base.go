@@ -29,3 +29,7 @@ func (e *envReader) LookupEnv(key string) (string, bool) {
 func (e *envReader) Environ() []string {
 	return os.Environ()
 }
+
+func (e *envReader) Unsetenv(key string) error {
+	return os.Unsetenv(key)
+}
@@ -50,3 +50,15 @@ func (m *multiEnvReader) Environ() []string {
 	}
 	return result
 }
+
+// Unsetenv unsets the env var from all the provided readers that implement Unsetter
+func (m *multiEnvReader) Unsetenv(key string) error {
+	for _, reader := range m.readers {
+		if u, ok := reader.(Unsetter); ok {
+			if err := u.Unsetenv(key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
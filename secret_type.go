@@ -0,0 +1,132 @@
+package cfgenv
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Secret is a config field type that holds a secret value of type T - loaded like any field of type T (including
+// defaults, decoders and validation) but never revealed by fmt (e.g. "%v", "%+v" or "%#v") or JSON marshalling
+//
+// The value is only available via Reveal - and Write masks the value (see Redactor) unless a RevealSecretsOption
+// is passed, e.g.
+//
+//	type Config struct {
+//	    DbPassword cfgenv.Secret[string]
+//	}
+//	...
+//	db.Connect(cfg.DbPassword.Reveal())
+type Secret[T any] struct {
+	value T
+}
+
+// NewSecret creates a new Secret holding the value
+func NewSecret[T any](value T) Secret[T] {
+	return Secret[T]{value: value}
+}
+
+// Reveal returns the secret value
+func (s Secret[T]) Reveal() T {
+	return s.value
+}
+
+// String returns the redacted placeholder (never the value)
+func (s Secret[T]) String() string {
+	return defaultMask
+}
+
+// GoString returns the redacted placeholder (never the value)
+func (s Secret[T]) GoString() string {
+	return defaultMask
+}
+
+// Format writes the redacted placeholder (never the value) for any verb
+func (s Secret[T]) Format(f fmt.State, _ rune) {
+	_, _ = f.Write([]byte(defaultMask))
+}
+
+// MarshalJSON marshals the redacted placeholder (never the value)
+func (s Secret[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(defaultMask)
+}
+
+func (s *Secret[T]) secretValue() reflect.Value {
+	return reflect.ValueOf(&s.value).Elem()
+}
+
+// secretHolder is implemented by (pointers to) Secret
+type secretHolder interface {
+	secretValue() reflect.Value
+}
+
+var secretHolderType = reflect.TypeOf((*secretHolder)(nil)).Elem()
+
+// isSecretType determines whether the type is a Secret[T]
+func isSecretType(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && reflect.PointerTo(t).Implements(secretHolderType)
+}
+
+// isNestedSecretType determines whether the type has a Secret[T] as its pointer, slice, array or map item (or key)
+// type - which are not supported (use Secret[*T], Secret[[]T] or Secret[map[K]V] instead)
+func isNestedSecretType(t reflect.Type) bool {
+	for nested := false; ; nested = true {
+		switch t.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Array:
+			t = t.Elem()
+		case reflect.Map:
+			if isSecretType(t.Key()) {
+				return true
+			}
+			t = t.Elem()
+		default:
+			return nested && isSecretType(t)
+		}
+	}
+}
+
+// unwrapSecret returns the field and value of the T for a Secret[T] field (and true) - otherwise the field and value as is
+//
+// where the value is not addressable (e.g. when writing a map of structs), the returned value is of a copy
+func unwrapSecret(fld reflect.StructField, fv reflect.Value) (reflect.StructField, reflect.Value, bool) {
+	if !isSecretType(fld.Type) {
+		return fld, fv, false
+	}
+	if !fv.CanAddr() {
+		cv := reflect.New(fv.Type()).Elem()
+		cv.Set(fv)
+		fv = cv
+	}
+	iv := fv.Addr().Interface().(secretHolder).secretValue()
+	fld.Type = iv.Type()
+	return fld, iv, true
+}
+
+// wrapSecret marks the field info of the T of a Secret[T] field as secret
+func (fi *fieldInfo) wrapSecret(fld reflect.StructField) error {
+	if fi.isStruct || fi.isStructSlice || fi.isStructMap {
+		return newUnsupportedTypeError(fld, "field '%s' has unsupported secret type - %s", fld.Name, fld.Type.String())
+	}
+	fi.secret = true
+	return nil
+}
+
+// RevealSecretsOption is an option that can be passed to Write
+// and determines whether secret values (see Secret, env tag `secret` and SecretNamesOption) are written as is - rather
+// than masked (see Redactor)
+type RevealSecretsOption interface {
+	// RevealSecrets returns whether secret values are written as is
+	RevealSecrets() bool
+}
+
+type revealSecretsOpt struct{}
+
+func (r *revealSecretsOpt) RevealSecrets() bool {
+	return true
+}
+
+// NewRevealSecrets creates a new RevealSecretsOption - where secret values are written as is (e.g. when writing an env
+// file to be loaded)
+func NewRevealSecrets() RevealSecretsOption {
+	return &revealSecretsOpt{}
+}
@@ -0,0 +1,188 @@
+package cfgenv
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"testing"
+)
+
+type testSecretTypeConfig struct {
+	Host     string
+	Password Secret[string]
+	Pin      Secret[int]    `env:"optional,default=1234"`
+	Key      Secret[[]byte] `env:"encoding=base64"`
+	Optional Secret[*string]
+	Hosts    Secret[[]string]       `env:"optional"`
+	Ports    Secret[map[string]int] `env:"optional"`
+}
+
+func TestSecret_NeverLeaks(t *testing.T) {
+	cfg := &testSecretTypeConfig{
+		Host:     "localhost",
+		Password: NewSecret("foo"),
+		Pin:      NewSecret(9876),
+	}
+	assert.Equal(t, "foo", cfg.Password.Reveal())
+	assert.Equal(t, 9876, cfg.Pin.Reveal())
+	for _, format := range []string{"%v", "%+v", "%#v", "%s", "%q", "%d", "%x"} {
+		s := fmt.Sprintf(format, cfg)
+		assert.NotContains(t, s, "foo", format)
+		assert.NotContains(t, s, "9876", format)
+		assert.NotContains(t, s, "2694", format)
+	}
+	assert.Equal(t, "******", fmt.Sprintf("%v", cfg.Password))
+	assert.Equal(t, "******", cfg.Password.String())
+	assert.Equal(t, "******", cfg.Password.GoString())
+	data, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "foo")
+	assert.Contains(t, string(data), `"Password":"******"`)
+}
+
+func TestLoad_SecretType(t *testing.T) {
+	cfg := &testSecretTypeConfig{}
+	err := Load(cfg, MapEnvReader{
+		"HOST":     "localhost",
+		"PASSWORD": "foo",
+		"KEY":      "aGVsbG8=",
+		"OPTIONAL": "bar",
+		"HOSTS":    "a,b",
+		"PORTS":    "a:1,b:2",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "foo", cfg.Password.Reveal())
+	assert.Equal(t, 1234, cfg.Pin.Reveal())
+	assert.Equal(t, []byte("hello"), cfg.Key.Reveal())
+	assert.Equal(t, "bar", *cfg.Optional.Reveal())
+	assert.Equal(t, []string{"a", "b"}, cfg.Hosts.Reveal())
+	assert.Equal(t, map[string]int{"a": 1, "b": 2}, cfg.Ports.Reveal())
+
+	cfg = &testSecretTypeConfig{}
+	err = Load(cfg, MapEnvReader{"HOST": "localhost", "KEY": "aGVsbG8=", "PIN": "12x34"})
+	require.Error(t, err)
+	assert.Equal(t, "missing env var 'PASSWORD'\nenv var 'PIN' is invalid (value redacted)", err.Error())
+	assert.True(t, errors.Is(err, strconv.ErrSyntax))
+	assert.Nil(t, cfg.Optional.Reveal())
+}
+
+func TestLoad_SecretType_Unsupported(t *testing.T) {
+	type sub struct {
+		Host string
+	}
+	err := Load(&struct {
+		Sub Secret[sub]
+	}{}, MapEnvReader{})
+	require.Error(t, err)
+	assert.Equal(t, "field 'Sub' has unsupported secret type - cfgenv.sub", err.Error())
+	var ute *UnsupportedTypeError
+	assert.True(t, errors.As(err, &ute))
+}
+
+func TestLoad_SecretType_Nested(t *testing.T) {
+	testCases := []struct {
+		cfg    any
+		expect string
+	}{
+		{
+			cfg: &struct {
+				P *Secret[string]
+			}{},
+			expect: "field 'P' has unsupported secret type - *cfgenv.Secret[string] (use Secret as the field type, e.g. Secret[*T] or Secret[[]T])",
+		},
+		{
+			cfg: &struct {
+				P []Secret[string]
+			}{},
+			expect: "field 'P' has unsupported secret type - []cfgenv.Secret[string] (use Secret as the field type, e.g. Secret[*T] or Secret[[]T])",
+		},
+		{
+			cfg: &struct {
+				P []*Secret[string]
+			}{},
+			expect: "field 'P' has unsupported secret type - []*cfgenv.Secret[string] (use Secret as the field type, e.g. Secret[*T] or Secret[[]T])",
+		},
+		{
+			cfg: &struct {
+				P [2]Secret[int]
+			}{},
+			expect: "field 'P' has unsupported secret type - [2]cfgenv.Secret[int] (use Secret as the field type, e.g. Secret[*T] or Secret[[]T])",
+		},
+		{
+			cfg: &struct {
+				P map[string]Secret[string] `env:"prefix=P_"`
+			}{},
+			expect: "field 'P' has unsupported secret type - map[string]cfgenv.Secret[string] (use Secret as the field type, e.g. Secret[*T] or Secret[[]T])",
+		},
+		{
+			cfg: &struct {
+				P Secret[[]Secret[string]]
+			}{},
+			expect: "field 'P' has unsupported secret type - []cfgenv.Secret[string] (use Secret as the field type, e.g. Secret[*T] or Secret[[]T])",
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("[%d]", i+1), func(t *testing.T) {
+			err := Load(tc.cfg, MapEnvReader{"P": "pw", "P_0": "pw", "P_A": "pw"})
+			require.Error(t, err)
+			assert.Equal(t, tc.expect, err.Error())
+			var ute *UnsupportedTypeError
+			assert.True(t, errors.As(err, &ute))
+
+			err = Example(&bytes.Buffer{}, tc.cfg)
+			require.Error(t, err)
+			assert.Equal(t, tc.expect, err.Error())
+		})
+	}
+}
+
+func TestWrite_SecretType(t *testing.T) {
+	cfg := &testSecretTypeConfig{
+		Host:     "localhost",
+		Password: NewSecret("foo"),
+		Pin:      NewSecret(1234),
+		Key:      NewSecret([]byte("hello")),
+		Hosts:    NewSecret([]string{"a", "b"}),
+	}
+	var w bytes.Buffer
+	err := Write(&w, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "HOST=localhost\nPASSWORD=******\nPIN=******\nKEY=******\nHOSTS=******\nPORTS=******\n", w.String())
+
+	w.Reset()
+	err = Write(&w, cfg, NewRevealSecrets())
+	require.NoError(t, err)
+	assert.Equal(t, "HOST=localhost\nPASSWORD=foo\nPIN=1234\nKEY=hello\nHOSTS=a,b\nPORTS=\n", w.String())
+
+	w.Reset()
+	err = Example(&w, &testSecretTypeConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, "HOST=<string>\nPASSWORD=<secret>\nPIN=<secret>\nKEY=<secret>\nOPTIONAL=<secret>\nHOSTS=<secret>\nPORTS=<secret>\n", w.String())
+
+	err = Write(&w, cfg, NewRevealSecrets(), NewRevealSecrets())
+	require.Error(t, err)
+	assert.Equal(t, "multiple reveal secrets options", err.Error())
+}
+
+func TestWrite_SecretType_StructMap(t *testing.T) {
+	type item struct {
+		Token Secret[string]
+	}
+	type config struct {
+		Items map[string]item `env:"prefix=ITEM"`
+	}
+	cfg := &config{Items: map[string]item{"A": {Token: NewSecret("foo")}}}
+	var w bytes.Buffer
+	err := Write(&w, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "ITEM_A_TOKEN=******\n", w.String())
+
+	w.Reset()
+	err = Write(&w, cfg, NewRevealSecrets())
+	require.NoError(t, err)
+	assert.Equal(t, "ITEM_A_TOKEN=foo\n", w.String())
+}
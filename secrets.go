@@ -0,0 +1,154 @@
+package cfgenv
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// SecretNamesOption is an option that can be passed to Load, LoadAs, Write, Example or ExampleOf
+// and determines which env vars are secret by name (in addition to fields with the `secret` env tag)
+//
+// Secret values are redacted from errors and masked when written (see Redactor)
+type SecretNamesOption interface {
+	// IsSecretName returns whether the env var name denotes a secret
+	IsSecretName(name string) bool
+}
+
+type secretNamesOpt struct {
+	words map[string]bool
+}
+
+// defaultSecretWords are the name words that denote a secret (used by NewSecretNames when no words are specified)
+var defaultSecretWords = []string{"PASSWORD", "TOKEN", "KEY", "SECRET"}
+
+// NewSecretNames creates a new SecretNamesOption - where an env var is secret if any word of its name (e.g. "DB" and
+// "PASSWORD" in "DB_PASSWORD") matches, case-insensitively, any of the specified words
+//
+// If no words are specified, the words "PASSWORD", "TOKEN", "KEY" and "SECRET" are used
+func NewSecretNames(words ...string) SecretNamesOption {
+	if len(words) == 0 {
+		words = defaultSecretWords
+	}
+	result := &secretNamesOpt{words: make(map[string]bool, len(words))}
+	for _, w := range words {
+		result.words[strings.ToUpper(w)] = true
+	}
+	return result
+}
+
+func (s *secretNamesOpt) IsSecretName(name string) bool {
+	for _, w := range strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if s.words[strings.ToUpper(w)] {
+			return true
+		}
+	}
+	return false
+}
+
+// Redactor is an option that can be passed to Write
+// and determines how secret values (see env tag `secret` and SecretNamesOption) are written
+//
+// If no Redactor is passed, secret values are written as "******"
+type Redactor interface {
+	// Redact returns the redacted value written in place of the secret value
+	Redact(value string) string
+}
+
+type maskRedactor struct {
+	mask string
+}
+
+// NewMaskRedactor creates a new Redactor that writes all secret values as the mask (if the mask is empty, "******" is used)
+func NewMaskRedactor(mask string) Redactor {
+	if mask == "" {
+		mask = defaultMask
+	}
+	return &maskRedactor{mask: mask}
+}
+
+func (m *maskRedactor) Redact(string) string {
+	return m.mask
+}
+
+const defaultMask = "******"
+
+var defaultRedactor = NewMaskRedactor(defaultMask)
+
+type hashRedactor struct {
+	length int
+}
+
+// NewHashRedactor creates a new Redactor that writes secret values as a prefix of the hex SHA-256 hash of the value (e.g.
+// "sha256:2bb80d53") - so that values can be compared (e.g. across startup logs) without being revealed
+//
+// length is the number of hex digits written (if less than 1, 8 is used)
+func NewHashRedactor(length int) Redactor {
+	if length < 1 {
+		length = 8
+	} else if length > sha256.Size*2 {
+		length = sha256.Size * 2
+	}
+	return &hashRedactor{length: length}
+}
+
+func (h *hashRedactor) Redact(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return "sha256:" + hex.EncodeToString(sum[:])[:h.length]
+}
+
+// isSecret determines whether the env var for a field is secret
+func (o *opts) isSecret(name string, fi *fieldInfo) bool {
+	return fi.secret || (o.secretNames != nil && o.secretNames.IsSecretName(name))
+}
+
+// redact returns the redacted value to be written for a secret (or the value as is, if secrets are revealed)
+func (o *opts) redact(value string) string {
+	if o.revealSecrets {
+		return value
+	} else if o.redactor != nil {
+		return o.redactor.Redact(value)
+	}
+	return defaultRedactor.Redact(value)
+}
+
+// redactedError wraps the cause of an error for a secret field - the cause remains available to errors.Is and
+// errors.As but its message (which may contain the value) is not used
+type redactedError struct {
+	err error
+}
+
+func (e *redactedError) Error() string {
+	return "value redacted"
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+func redactedCause(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &redactedError{err: err}
+}
+
+// redactError removes any mention of the value from an error for a secret field
+func redactError(err error, name string, t reflect.Type) error {
+	switch et := err.(type) {
+	case nil, *MissingVarError, *FileError, *AliasConflictError, *TagError, *UnsupportedTypeError, *ConstraintError:
+		return err
+	case *ParseError:
+		return &ParseError{Name: et.Name, Field: et.Field, Type: et.Type, Err: redactedCause(et.Err), msg: "is invalid (value redacted)"}
+	case *ValidationError:
+		return &ValidationError{Name: et.Name, Field: et.Field, Type: et.Type, Rule: et.Rule, Err: redactedCause(et.Err), msg: "value redacted"}
+	case *DecodeError:
+		return &DecodeError{Name: et.Name, Field: et.Field, Type: et.Type, Encoding: et.Encoding, Err: redactedCause(et.Err)}
+	}
+	// e.g. errors from a CustomSetterOption...
+	return &ParseError{Name: name, Type: t, Err: redactedCause(err), msg: "is invalid (value redacted)"}
+}
@@ -0,0 +1,161 @@
+package cfgenv
+
+import (
+	"bytes"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+type testSecretConfig struct {
+	Host     string
+	Password string `env:"secret"`
+	ApiKey   string
+	Pin      int      `env:"secret,optional,min=1000"`
+	Hosts    []string `env:"secret,optional"`
+	Key      []byte   `env:"secret,optional,encoding=base64"`
+	Settings struct {
+		Token string
+	} `env:"json,optional"`
+}
+
+func TestLoad_Secrets(t *testing.T) {
+	cfg := &testSecretConfig{}
+	err := Load(cfg, MapEnvReader{"HOST": "localhost", "PASSWORD": "foo", "API_KEY": "bar", "PIN": "1234"})
+	require.NoError(t, err)
+	assert.Equal(t, "foo", cfg.Password)
+	assert.Equal(t, 1234, cfg.Pin)
+
+	err = Load(cfg, MapEnvReader{"PASSWORD": "foo", "API_KEY": "bar", "PIN": "12x34"})
+	require.Error(t, err)
+	assert.Equal(t, "missing env var 'HOST'\nenv var 'PIN' is invalid (value redacted)", err.Error())
+	assert.NotContains(t, err.Error(), "12x34")
+	assert.True(t, errors.Is(err, strconv.ErrSyntax))
+	var pe *ParseError
+	require.True(t, errors.As(err, &pe))
+	assert.Equal(t, "Pin", pe.Field)
+
+	err = Load(cfg, MapEnvReader{"HOST": "localhost", "PASSWORD": "foo", "API_KEY": "bar", "PIN": "999", "KEY": "!!!"})
+	require.Error(t, err)
+	assert.Equal(t, "env var 'PIN' failed validation 'min=1000' - value redacted\nunable to decode env var 'KEY' (encoding: 'base64'): value redacted", err.Error())
+	var ve *ValidationError
+	require.True(t, errors.As(err, &ve))
+	assert.Equal(t, "min=1000", ve.Rule)
+}
+
+func TestLoad_SecretNames(t *testing.T) {
+	type config struct {
+		Port   int
+		ApiKey int
+	}
+	err := Load(&config{}, MapEnvReader{"PORT": "x", "API_KEY": "y"})
+	require.Error(t, err)
+	assert.Equal(t, "env var 'PORT' is not an int\nenv var 'API_KEY' is not an int", err.Error())
+
+	err = Load(&config{}, NewSecretNames(), MapEnvReader{"PORT": "x", "API_KEY": "y"})
+	require.Error(t, err)
+	assert.Equal(t, "env var 'PORT' is not an int\nenv var 'API_KEY' is invalid (value redacted)", err.Error())
+
+	err = Load(&config{}, NewSecretNames(), NewSecretNames())
+	require.Error(t, err)
+	assert.Equal(t, "multiple secret names options", err.Error())
+}
+
+func TestLoad_Secrets_CustomSetter(t *testing.T) {
+	type config struct {
+		Password string `env:"secret"`
+	}
+	cause := errors.New("bad value 'foo'")
+	err := Load(&config{}, &failingSetter{err: cause}, MapEnvReader{"PASSWORD": "foo"})
+	require.Error(t, err)
+	assert.Equal(t, "env var 'PASSWORD' is invalid (value redacted)", err.Error())
+	assert.True(t, errors.Is(err, cause))
+
+	err = Load(&struct {
+		Sub struct{} `env:"secret"`
+	}{}, MapEnvReader{})
+	require.Error(t, err)
+	assert.Equal(t, "cannot use env tag 'secret' on struct field 'Sub'", err.Error())
+}
+
+func TestSecretNames(t *testing.T) {
+	testCases := []struct {
+		name   string
+		words  []string
+		expect bool
+	}{
+		{name: "DB_PASSWORD", expect: true},
+		{name: "API_KEY", expect: true},
+		{name: "api_key", expect: true},
+		{name: "AUTH_TOKEN_TTL", expect: true},
+		{name: "CLIENT_SECRET", expect: true},
+		{name: "MONKEY", expect: false},
+		{name: "KEYTRIM", expect: false},
+		{name: "HOST", expect: false},
+		{name: "DB_PASS", expect: false},
+		{name: "DB_PASS", words: []string{"pass"}, expect: true},
+		{name: "DB_PASSWORD", words: []string{"pass"}, expect: false},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i+1)+" "+tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expect, NewSecretNames(tc.words...).IsSecretName(tc.name))
+		})
+	}
+}
+
+func TestWrite_Secrets(t *testing.T) {
+	cfg := &testSecretConfig{
+		Host:     "localhost",
+		Password: "foo",
+		ApiKey:   "bar",
+		Pin:      1234,
+		Hosts:    []string{"a", "b"},
+		Key:      []byte("hello"),
+	}
+	cfg.Settings.Token = "baz"
+	var w bytes.Buffer
+	err := Write(&w, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "HOST=localhost\nPASSWORD=******\nAPI_KEY=bar\nPIN=******\nHOSTS=******\nKEY=******\nSETTINGS={\"Token\":\"baz\"}\n", w.String())
+
+	w.Reset()
+	err = Write(&w, cfg, NewSecretNames(), NewMaskRedactor("[redacted]"))
+	require.NoError(t, err)
+	assert.Equal(t, "HOST=localhost\nPASSWORD=[redacted]\nAPI_KEY=[redacted]\nPIN=[redacted]\nHOSTS=[redacted]\nKEY=[redacted]\nSETTINGS={\"Token\":\"baz\"}\n", w.String())
+
+	w.Reset()
+	err = Write(&w, cfg, NewHashRedactor(0))
+	require.NoError(t, err)
+	assert.Equal(t, "HOST=localhost\nPASSWORD=sha256:2c26b46b\nAPI_KEY=bar\nPIN=sha256:03ac6742\nHOSTS=sha256:1eb7c54d\nKEY=sha256:2cf24dba\nSETTINGS={\"Token\":\"baz\"}\n", w.String())
+
+	w.Reset()
+	err = Example(&w, &testSecretConfig{}, NewSecretNames())
+	require.NoError(t, err)
+	assert.Equal(t, "HOST=<string>\nPASSWORD=<secret>\nAPI_KEY=<secret>\nPIN=<secret>\nHOSTS=<secret>\nKEY=<secret>\nSETTINGS={\"Token\":\"\"}\n", w.String())
+
+	err = Write(&w, cfg, NewHashRedactor(0), NewMaskRedactor(""))
+	require.Error(t, err)
+	assert.Equal(t, "multiple redactors", err.Error())
+}
+
+func TestHashRedactor(t *testing.T) {
+	assert.Equal(t, "sha256:2c26", NewHashRedactor(4).Redact("foo"))
+	assert.Equal(t, "sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae", NewHashRedactor(100).Redact("foo"))
+}
+
+type failingSetter struct {
+	err error
+}
+
+var _ CustomSetterOption = &failingSetter{}
+
+func (s *failingSetter) IsApplicable(fld reflect.StructField) bool {
+	return true
+}
+
+func (s *failingSetter) Set(fld reflect.StructField, v reflect.Value, raw string, present bool) error {
+	return s.err
+}
@@ -0,0 +1,249 @@
+package cfgenv
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// stdType is a standard library type supported without the need for a CustomSetterOption
+type stdType struct {
+	// desc describes the type (used in parse error messages)
+	desc string
+	// example is the example value written by Example
+	example string
+	// parse parses the raw value - returning a pointer to the type
+	parse func(raw string) (any, error)
+	// format formats the value (the value is never a pointer)
+	format func(v any) string
+}
+
+// parseTCPAddr parses an IP address and port (or just ":port") - hostnames are not resolved (so that loading never
+// performs DNS lookups) and are invalid
+func parseTCPAddr(raw string) (any, error) {
+	host, port, err := net.SplitHostPort(raw)
+	if err != nil {
+		return nil, err
+	}
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port '%s'", port)
+	}
+	result := &net.TCPAddr{Port: int(p)}
+	if host != "" {
+		addr, err := netip.ParseAddr(host)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IP address '%s' (hostnames are not resolved)", host)
+		}
+		result.IP = addr.AsSlice()
+		result.Zone = addr.Zone()
+	}
+	return result, nil
+}
+
+var stdTypes = map[reflect.Type]*stdType{
+	reflect.TypeOf(url.URL{}): {
+		desc:    "a URL",
+		example: "<url>",
+		parse: func(raw string) (any, error) {
+			return url.Parse(raw)
+		},
+		format: func(v any) string {
+			u := v.(url.URL)
+			return u.String()
+		},
+	},
+	reflect.TypeOf(net.IP{}): {
+		desc:    "an IP address",
+		example: "<ip>",
+		parse: func(raw string) (any, error) {
+			if ip := net.ParseIP(raw); ip != nil {
+				return &ip, nil
+			}
+			return nil, errors.New("invalid IP address")
+		},
+		format: func(v any) string {
+			return v.(net.IP).String()
+		},
+	},
+	reflect.TypeOf(net.IPNet{}): {
+		desc:    "a CIDR",
+		example: "<ip>/<bits>",
+		parse: func(raw string) (any, error) {
+			_, ipNet, err := net.ParseCIDR(raw)
+			return ipNet, err
+		},
+		format: func(v any) string {
+			ipNet := v.(net.IPNet)
+			return ipNet.String()
+		},
+	},
+	reflect.TypeOf(netip.Addr{}): {
+		desc:    "an IP address",
+		example: "<ip>",
+		parse: func(raw string) (any, error) {
+			addr, err := netip.ParseAddr(raw)
+			return &addr, err
+		},
+		format: func(v any) string {
+			return v.(netip.Addr).String()
+		},
+	},
+	reflect.TypeOf(netip.AddrPort{}): {
+		desc:    "an IP address and port",
+		example: "<ip>:<port>",
+		parse: func(raw string) (any, error) {
+			addrPort, err := netip.ParseAddrPort(raw)
+			return &addrPort, err
+		},
+		format: func(v any) string {
+			return v.(netip.AddrPort).String()
+		},
+	},
+	reflect.TypeOf(netip.Prefix{}): {
+		desc:    "a CIDR",
+		example: "<ip>/<bits>",
+		parse: func(raw string) (any, error) {
+			prefix, err := netip.ParsePrefix(raw)
+			return &prefix, err
+		},
+		format: func(v any) string {
+			return v.(netip.Prefix).String()
+		},
+	},
+	reflect.TypeOf(regexp.Regexp{}): {
+		desc:    "a regexp",
+		example: "<regexp>",
+		parse: func(raw string) (any, error) {
+			return regexp.Compile(raw)
+		},
+		format: func(v any) string {
+			rx := v.(regexp.Regexp)
+			return rx.String()
+		},
+	},
+	reflect.TypeOf(time.Location{}): {
+		desc:    "a time zone location",
+		example: "<location>",
+		parse: func(raw string) (any, error) {
+			return time.LoadLocation(raw)
+		},
+		format: func(v any) string {
+			loc := v.(time.Location)
+			return loc.String()
+		},
+	},
+	reflect.TypeOf(mail.Address{}): {
+		desc:    "an email address",
+		example: "<name> <email>",
+		parse: func(raw string) (any, error) {
+			return mail.ParseAddress(raw)
+		},
+		format: func(v any) string {
+			addr := v.(mail.Address)
+			return addr.String()
+		},
+	},
+	reflect.TypeOf(net.TCPAddr{}): {
+		desc:    "a TCP address",
+		example: "<ip>:<port>",
+		parse:   parseTCPAddr,
+		format: func(v any) string {
+			addr := v.(net.TCPAddr)
+			return addr.String()
+		},
+	},
+	reflect.TypeOf(big.Int{}): {
+		desc:    "an integer",
+		example: "0",
+		parse: func(raw string) (any, error) {
+			if i, ok := new(big.Int).SetString(raw, 0); ok {
+				return i, nil
+			}
+			return nil, errors.New("invalid integer")
+		},
+		format: func(v any) string {
+			i := v.(big.Int)
+			return i.String()
+		},
+	},
+	reflect.TypeOf(big.Float{}): {
+		desc:    "a float",
+		example: "0.0",
+		parse: func(raw string) (any, error) {
+			// precision is sufficient for all the decimal digits (and at least that of a float64)...
+			prec := uint(math.Ceil(float64(len(raw)) * math.Log2(10)))
+			if prec < 64 {
+				prec = 64
+			}
+			f, _, err := big.ParseFloat(raw, 0, prec, big.ToNearestEven)
+			return f, err
+		},
+		format: func(v any) string {
+			f := v.(big.Float)
+			return f.Text('g', -1)
+		},
+	},
+	reflect.TypeOf(big.Rat{}): {
+		desc:    "a rational number",
+		example: "0",
+		parse: func(raw string) (any, error) {
+			if r, ok := new(big.Rat).SetString(raw); ok {
+				return r, nil
+			}
+			return nil, errors.New("invalid rational number")
+		},
+		format: func(v any) string {
+			r := v.(big.Rat)
+			if s, ok := exactDecimal(&r, 20); ok {
+				return s
+			}
+			return r.RatString()
+		},
+	},
+	reflect.TypeOf(os.FileMode(0)): {
+		desc:    "a file mode",
+		example: "0644",
+		parse: func(raw string) (any, error) {
+			mode, err := strconv.ParseUint(strings.TrimPrefix(raw, "0o"), 8, 32)
+			fm := os.FileMode(mode)
+			return &fm, err
+		},
+		format: func(v any) string {
+			return fmt.Sprintf("%#o", uint32(v.(os.FileMode)))
+		},
+	},
+}
+
+// stdTypeOf returns the supported standard library type (if any) of the type (or pointer to type)
+func stdTypeOf(t reflect.Type) *stdType {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return stdTypes[t]
+}
+
+// setStdValue sets a value of a supported standard library type (or pointer to type)
+func setStdValue(st *stdType, raw string, fv reflect.Value) error {
+	pv, err := st.parse(raw)
+	if err != nil {
+		return err
+	}
+	if fv.Kind() == reflect.Pointer {
+		fv.Set(reflect.ValueOf(pv))
+	} else {
+		fv.Set(reflect.ValueOf(pv).Elem())
+	}
+	return nil
+}
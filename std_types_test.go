@@ -0,0 +1,283 @@
+package cfgenv
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/go-andiamo/gopt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+)
+
+type testStdTypes struct {
+	Url      *url.URL
+	Ip       net.IP
+	IpNet    net.IPNet
+	Addr     netip.Addr
+	AddrPort netip.AddrPort
+	Prefix   netip.Prefix
+	Regexp   *regexp.Regexp
+	Location *time.Location
+	Email    mail.Address
+	TcpAddr  *net.TCPAddr
+	Mode     os.FileMode
+}
+
+func TestLoad_StdTypes(t *testing.T) {
+	cfg := &testStdTypes{}
+	err := Load(cfg, MapEnvReader{
+		"URL":       "https://example.com/path?q=1",
+		"IP":        "10.0.0.1",
+		"IP_NET":    "10.0.0.0/8",
+		"ADDR":      "::1",
+		"ADDR_PORT": "127.0.0.1:8080",
+		"PREFIX":    "192.168.0.0/16",
+		"REGEXP":    "^[a-z]+$",
+		"LOCATION":  "UTC",
+		"EMAIL":     "Bob <bob@example.com>",
+		"TCP_ADDR":  "127.0.0.1:9000",
+		"MODE":      "0644",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "example.com", cfg.Url.Host)
+	assert.Equal(t, "10.0.0.1", cfg.Ip.String())
+	assert.Equal(t, "10.0.0.0/8", cfg.IpNet.String())
+	assert.Equal(t, netip.MustParseAddr("::1"), cfg.Addr)
+	assert.Equal(t, uint16(8080), cfg.AddrPort.Port())
+	assert.Equal(t, 16, cfg.Prefix.Bits())
+	assert.True(t, cfg.Regexp.MatchString("abc"))
+	assert.Equal(t, time.UTC.String(), cfg.Location.String())
+	assert.Equal(t, mail.Address{Name: "Bob", Address: "bob@example.com"}, cfg.Email)
+	assert.Equal(t, 9000, cfg.TcpAddr.Port)
+	assert.Equal(t, os.FileMode(0644), cfg.Mode)
+}
+
+func TestLoad_StdTypes_Collections(t *testing.T) {
+	type config struct {
+		Urls      []*url.URL
+		Nets      []netip.Prefix
+		Patterns  map[string]*regexp.Regexp
+		Modes     map[os.FileMode]string
+		Location  gopt.Optional[*time.Location]
+		Missing   gopt.Optional[netip.Addr]
+		OptIp     *netip.Addr
+		Addresses []mail.Address `env:"delim=;"`
+	}
+	cfg := &config{}
+	err := Load(cfg, MapEnvReader{
+		"URLS":      "https://a.com,https://b.com",
+		"NETS":      "10.0.0.0/8,172.16.0.0/12",
+		"PATTERNS":  "x:^x$,y:^y+$",
+		"MODES":     "0600:private,0o755:exec",
+		"LOCATION":  "UTC",
+		"OPT_IP":    "10.0.0.1",
+		"ADDRESSES": "a@example.com;B <b@example.com>",
+	})
+	require.NoError(t, err)
+	require.Len(t, cfg.Urls, 2)
+	assert.Equal(t, "b.com", cfg.Urls[1].Host)
+	assert.Equal(t, []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("172.16.0.0/12")}, cfg.Nets)
+	assert.True(t, cfg.Patterns["y"].MatchString("yy"))
+	assert.Equal(t, map[os.FileMode]string{0600: "private", 0755: "exec"}, cfg.Modes)
+	assert.True(t, cfg.Location.IsPresent())
+	assert.Equal(t, "UTC", cfg.Location.Default(nil).String())
+	assert.False(t, cfg.Missing.IsPresent())
+	assert.Equal(t, "10.0.0.1", cfg.OptIp.String())
+	assert.Equal(t, []mail.Address{{Address: "a@example.com"}, {Name: "B", Address: "b@example.com"}}, cfg.Addresses)
+}
+
+func TestLoad_StdTypes_Errors(t *testing.T) {
+	cfg := &testStdTypes{}
+	err := Load(cfg, MapEnvReader{
+		"URL":       "://x",
+		"IP":        "x",
+		"IP_NET":    "x",
+		"ADDR":      "x",
+		"ADDR_PORT": "x",
+		"PREFIX":    "x",
+		"REGEXP":    "(",
+		"LOCATION":  "Nowhere/Special",
+		"EMAIL":     "x",
+		"TCP_ADDR":  "x",
+		"MODE":      "9",
+	})
+	require.Error(t, err)
+	errs := err.(*LoadErrors).Errors
+	require.Len(t, errs, 11)
+	assert.Equal(t, "env var 'URL' is not a URL", errs[0].Error())
+	assert.Equal(t, "env var 'IP' is not an IP address", errs[1].Error())
+	assert.Equal(t, "env var 'IP_NET' is not a CIDR", errs[2].Error())
+	assert.Equal(t, "env var 'ADDR' is not an IP address", errs[3].Error())
+	assert.Equal(t, "env var 'ADDR_PORT' is not an IP address and port", errs[4].Error())
+	assert.Equal(t, "env var 'PREFIX' is not a CIDR", errs[5].Error())
+	assert.Equal(t, "env var 'REGEXP' is not a regexp", errs[6].Error())
+	assert.Equal(t, "env var 'LOCATION' is not a time zone location", errs[7].Error())
+	assert.Equal(t, "env var 'EMAIL' is not an email address", errs[8].Error())
+	assert.Equal(t, "env var 'TCP_ADDR' is not a TCP address", errs[9].Error())
+	assert.Equal(t, "env var 'MODE' is not a file mode", errs[10].Error())
+	var pe *ParseError
+	require.ErrorAs(t, errs[6], &pe)
+	assert.NotNil(t, pe.Err)
+}
+
+func TestLoad_StdTypes_TCPAddr(t *testing.T) {
+	testCases := []struct {
+		value       string
+		expect      string
+		expectError string
+	}{
+		{value: "127.0.0.1:9000", expect: "127.0.0.1:9000"},
+		{value: "[::1]:9000", expect: "[::1]:9000"},
+		{value: "[fe80::1%eth0]:9000", expect: "[fe80::1%eth0]:9000"},
+		{value: ":9000", expect: ":9000"},
+		{value: "no-such-host.invalid:5432", expectError: "invalid IP address 'no-such-host.invalid' (hostnames are not resolved)"},
+		{value: "localhost:5432", expectError: "invalid IP address 'localhost' (hostnames are not resolved)"},
+		{value: "127.0.0.1:http", expectError: "invalid port 'http'"},
+		{value: "127.0.0.1:65536", expectError: "invalid port '65536'"},
+		{value: "127.0.0.1", expectError: "address 127.0.0.1: missing port in address"},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("[%d]", i+1), func(t *testing.T) {
+			cfg := &struct {
+				TcpAddr *net.TCPAddr
+			}{}
+			err := Load(cfg, MapEnvReader{"TCP_ADDR": tc.value})
+			if tc.expectError != "" {
+				require.Error(t, err)
+				assert.Equal(t, "env var 'TCP_ADDR' is not a TCP address", err.Error())
+				var pe *ParseError
+				require.ErrorAs(t, err, &pe)
+				assert.Equal(t, tc.expectError, pe.Err.Error())
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.expect, cfg.TcpAddr.String())
+			}
+		})
+	}
+}
+
+func TestWrite_StdTypes(t *testing.T) {
+	cfg := &testStdTypes{}
+	err := Load(cfg, MapEnvReader{
+		"URL":       "https://example.com/path",
+		"IP":        "10.0.0.1",
+		"IP_NET":    "10.0.0.0/8",
+		"ADDR":      "::1",
+		"ADDR_PORT": "127.0.0.1:8080",
+		"PREFIX":    "192.168.0.0/16",
+		"REGEXP":    "^[a-z]+$",
+		"LOCATION":  "UTC",
+		"EMAIL":     "bob@example.com",
+		"TCP_ADDR":  "127.0.0.1:9000",
+		"MODE":      "0644",
+	})
+	require.NoError(t, err)
+	var w bytes.Buffer
+	err = Write(&w, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, `URL=https://example.com/path
+IP=10.0.0.1
+IP_NET=10.0.0.0/8
+ADDR=::1
+ADDR_PORT=127.0.0.1:8080
+PREFIX=192.168.0.0/16
+REGEXP=^[a-z]+$
+LOCATION=UTC
+EMAIL=<bob@example.com>
+TCP_ADDR=127.0.0.1:9000
+MODE=0644
+`, w.String())
+
+	w.Reset()
+	err = Example(&w, &testStdTypes{})
+	require.NoError(t, err)
+	assert.Equal(t, `URL=<url>
+IP=<ip>
+IP_NET=<ip>/<bits>
+ADDR=<ip>
+ADDR_PORT=<ip>:<port>
+PREFIX=<ip>/<bits>
+REGEXP=<regexp>
+LOCATION=<location>
+EMAIL=<name> <email>
+TCP_ADDR=<ip>:<port>
+MODE=0644
+`, w.String())
+}
+
+func TestLoad_BigTypes(t *testing.T) {
+	type config struct {
+		Int      *big.Int
+		Hex      big.Int
+		Float    *big.Float
+		Rat      *big.Rat
+		Fraction big.Rat
+		Amounts  []*big.Rat
+		Optional gopt.Optional[*big.Int]
+	}
+	cfg := &config{}
+	err := Load(cfg, MapEnvReader{
+		"INT":      "123456789012345678901234567890",
+		"HEX":      "0xff",
+		"FLOAT":    "3.14159265358979323846264338327950288",
+		"RAT":      "1/3",
+		"FRACTION": "0.75",
+		"AMOUNTS":  "10.01,0.1",
+		"OPTIONAL": "-1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "123456789012345678901234567890", cfg.Int.String())
+	assert.Equal(t, int64(255), cfg.Hex.Int64())
+	assert.Equal(t, "3.14159265358979323846264338327950288", cfg.Float.Text('g', -1))
+	assert.Equal(t, "1/3", cfg.Rat.RatString())
+	assert.Equal(t, "3/4", cfg.Fraction.RatString())
+	assert.Equal(t, "1001/100", cfg.Amounts[0].RatString())
+	assert.Equal(t, int64(-1), cfg.Optional.Default(nil).Int64())
+
+	err = Load(cfg, MapEnvReader{"INT": "1.5", "HEX": "x", "FLOAT": "x", "RAT": "1/0", "FRACTION": "1", "AMOUNTS": "1"})
+	require.Error(t, err)
+	errs := err.(*LoadErrors).Errors
+	require.Len(t, errs, 4)
+	assert.Equal(t, "env var 'INT' is not an integer", errs[0].Error())
+	assert.Equal(t, "env var 'HEX' is not an integer", errs[1].Error())
+	assert.Equal(t, "env var 'FLOAT' is not a float", errs[2].Error())
+	assert.Equal(t, "env var 'RAT' is not a rational number", errs[3].Error())
+}
+
+func TestWrite_BigTypes(t *testing.T) {
+	type config struct {
+		Int      *big.Int
+		Float    *big.Float
+		Rat      *big.Rat
+		Fraction big.Rat
+	}
+	cfg := &config{}
+	err := Load(cfg, MapEnvReader{
+		"INT":      "123456789012345678901234567890",
+		"FLOAT":    "3.14159265358979323846264338327950288",
+		"RAT":      "1/3",
+		"FRACTION": "10.01",
+	})
+	require.NoError(t, err)
+	var w bytes.Buffer
+	err = Write(&w, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, `INT=123456789012345678901234567890
+FLOAT=3.14159265358979323846264338327950288
+RAT=1/3
+FRACTION=10.01
+`, w.String())
+
+	w.Reset()
+	err = Example(&w, &config{})
+	require.NoError(t, err)
+	assert.Equal(t, "INT=0\nFLOAT=0.0\nRAT=0\nFRACTION=0\n", w.String())
+}
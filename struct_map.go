@@ -0,0 +1,64 @@
+package cfgenv
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// setStructMap sets a map of structs (or pointers to structs) - where each map key is the env var name segment following
+// the prefix (e.g. prefix "DB" and env vars DB_PRIMARY_HOST, DB_REPLICA_HOST give keys "PRIMARY" and "REPLICA") and each
+// value is loaded from the env vars prefixed with the key
+func setStructMap(fv reflect.Value, prefix string, path string, options *opts) (bool, error) {
+	sep := options.separator.GetSeparator()
+	keys := envNameSegments(prefix, true, options)
+	errs := &LoadErrors{}
+	m := reflect.MakeMapWithSize(fv.Type(), len(keys))
+	kt := fv.Type().Key()
+	vt := fv.Type().Elem()
+	for _, k := range keys {
+		iv := reflect.New(vt).Elem()
+		sv := iv
+		if vt.Kind() == reflect.Pointer {
+			iv.Set(reflect.New(vt.Elem()))
+			sv = iv.Elem()
+		}
+		err := loadStruct(sv, prefix+sep+k, fmt.Sprintf("%s[%s]", path, k), options)
+		if err = options.collect(errs, err); err != nil {
+			return true, err
+		}
+		m.SetMapIndex(reflect.ValueOf(k).Convert(kt), iv)
+	}
+	fv.Set(m)
+	return len(keys) > 0, errs.errorOrNil()
+}
+
+// writeStructMap writes the items of a map of structs as env vars prefixed with the map key (for an example, a single
+// item is written with the key "<KEY>")
+func writeStructMap(out *[]EnvVar, fv reflect.Value, prefix string, actual bool, options *opts) error {
+	sep := options.separator.GetSeparator()
+	if !actual {
+		it := fv.Type().Elem()
+		if it.Kind() == reflect.Pointer {
+			it = it.Elem()
+		}
+		return write(out, reflect.New(it).Elem(), prefix+sep+"<KEY>", actual, options)
+	}
+	keys := fv.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+	for _, k := range keys {
+		iv := fv.MapIndex(k)
+		if iv.Kind() == reflect.Pointer {
+			if iv.IsNil() {
+				continue
+			}
+			iv = iv.Elem()
+		}
+		if err := write(out, iv, prefix+sep+k.String(), actual, options); err != nil {
+			return err
+		}
+	}
+	return nil
+}
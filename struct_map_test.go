@@ -0,0 +1,107 @@
+package cfgenv
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type testDbConfig struct {
+	Host     string
+	Port     int    `env:"optional,default=5432"`
+	Password string `env:"optional,encoding=base64"`
+}
+
+func TestLoad_StructMaps(t *testing.T) {
+	type config struct {
+		Dbs     map[string]testDbConfig  `env:"prefix=DB"`
+		Caches  map[string]*testDbConfig `env:"prefix=CACHE"`
+		Others  map[string]testDbConfig  `env:"prefix=OTHER"`
+		Timeout int                      `env:"DB_TIMEOUT"`
+	}
+	cfg := &config{}
+	err := Load(cfg, MapEnvReader{
+		"DB_PRIMARY_HOST":     "primary.example.com",
+		"DB_PRIMARY_PASSWORD": "c2VjcmV0",
+		"DB_REPLICA_HOST":     "replica.example.com",
+		"DB_REPLICA_PORT":     "5433",
+		"DB_TIMEOUT":          "10",
+		"CACHE_MAIN_HOST":     "cache.example.com",
+	})
+	require.NoError(t, err)
+	require.Len(t, cfg.Dbs, 2)
+	assert.Equal(t, testDbConfig{Host: "primary.example.com", Port: 5432, Password: "secret"}, cfg.Dbs["PRIMARY"])
+	assert.Equal(t, testDbConfig{Host: "replica.example.com", Port: 5433}, cfg.Dbs["REPLICA"])
+	require.Len(t, cfg.Caches, 1)
+	assert.Equal(t, "cache.example.com", cfg.Caches["MAIN"].Host)
+	assert.Empty(t, cfg.Others)
+	assert.Equal(t, 10, cfg.Timeout)
+}
+
+func TestLoad_StructMaps_WithNamedKeyType(t *testing.T) {
+	type shard string
+	type config struct {
+		Shards map[shard]testDbConfig `env:"prefix=SHARD"`
+	}
+	cfg := &config{}
+	err := Load(cfg, NewPrefix("APP"), MapEnvReader{
+		"APP_SHARD_EU_HOST": "eu.example.com",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "eu.example.com", cfg.Shards["EU"].Host)
+}
+
+func TestLoad_StructMaps_Errors(t *testing.T) {
+	type config struct {
+		Dbs map[string]testDbConfig `env:"prefix=DB,minlen=3"`
+	}
+	err := Load(&config{}, MapEnvReader{
+		"DB_PRIMARY_PORT": "x",
+		"DB_REPLICA_HOST": "replica.example.com",
+	})
+	require.Error(t, err)
+	errs := err.(*LoadErrors).Errors
+	require.Len(t, errs, 2)
+	assert.Equal(t, "missing env var 'DB_PRIMARY_HOST'", errs[0].Error())
+	assert.Equal(t, "Dbs[PRIMARY].Host", errs[0].(*MissingVarError).Field)
+	assert.Equal(t, "env var 'DB_PRIMARY_PORT' is not an int", errs[1].Error())
+
+	err = Load(&config{}, MapEnvReader{
+		"DB_PRIMARY_HOST": "primary.example.com",
+	})
+	require.Error(t, err)
+	assert.Equal(t, "env var 'DBS' failed validation 'minlen=3' - item count 1 is less than 3", err.Error())
+}
+
+func TestWrite_StructMaps(t *testing.T) {
+	type config struct {
+		Dbs map[string]*testDbConfig `env:"prefix=DB"`
+	}
+	cfg := &config{
+		Dbs: map[string]*testDbConfig{
+			"REPLICA": {Host: "replica.example.com", Port: 5433},
+			"PRIMARY": {Host: "primary.example.com", Port: 5432},
+		},
+	}
+	var w bytes.Buffer
+	err := Write(&w, cfg)
+	require.NoError(t, err)
+	const expect = `DB_PRIMARY_HOST=primary.example.com
+DB_PRIMARY_PORT=5432
+DB_PRIMARY_PASSWORD=
+DB_REPLICA_HOST=replica.example.com
+DB_REPLICA_PORT=5433
+DB_REPLICA_PASSWORD=
+`
+	assert.Equal(t, expect, w.String())
+
+	w.Reset()
+	err = Example(&w, cfg)
+	require.NoError(t, err)
+	const expectExample = `DB_<KEY>_HOST=<string>
+DB_<KEY>_PORT=5432
+DB_<KEY>_PASSWORD=<string>
+`
+	assert.Equal(t, expectExample, w.String())
+}
@@ -0,0 +1,91 @@
+package cfgenv
+
+import (
+	"encoding"
+	"flag"
+	"fmt"
+	"reflect"
+)
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+var flagValueType = reflect.TypeOf((*flag.Value)(nil)).Elem()
+
+// isTextType determines whether the type (or pointer to type) is a supported standard library type or implements
+// encoding.TextUnmarshaler or flag.Value
+func isTextType(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if stdTypes[t] != nil {
+		return true
+	}
+	pt := reflect.PointerTo(t)
+	return pt.Implements(textUnmarshalerType) || pt.Implements(flagValueType)
+}
+
+// setTextValue sets a value whose type is a supported standard library type or implements encoding.TextUnmarshaler or flag.Value
+func setTextValue(name string, raw string, fv reflect.Value) error {
+	if st := stdTypeOf(fv.Type()); st != nil {
+		if err := setStdValue(st, raw, fv); err != nil {
+			return &ParseError{Name: name, Type: fv.Type(), Err: err, msg: "is not " + st.desc}
+		}
+		return nil
+	} else if err := unmarshalText(raw, fv); err != nil {
+		return &ParseError{Name: name, Type: fv.Type(), Err: err}
+	}
+	return nil
+}
+
+func unmarshalText(raw string, fv reflect.Value) error {
+	if st := stdTypeOf(fv.Type()); st != nil {
+		return setStdValue(st, raw, fv)
+	}
+	t := fv.Type()
+	isPtr := t.Kind() == reflect.Pointer
+	if isPtr {
+		t = t.Elem()
+	}
+	pv := reflect.New(t)
+	var err error
+	switch tv := pv.Interface().(type) {
+	case encoding.TextUnmarshaler:
+		err = tv.UnmarshalText([]byte(raw))
+	case flag.Value:
+		err = tv.Set(raw)
+	}
+	if err == nil {
+		if isPtr {
+			fv.Set(pv)
+		} else {
+			fv.Set(pv.Elem())
+		}
+	}
+	return err
+}
+
+// textValue returns the text of a value whose type is a supported standard library type or implements encoding.TextMarshaler
+// or (for flag.Value) fmt.Stringer
+func textValue(fv reflect.Value) (string, bool) {
+	if fv.Kind() == reflect.Pointer {
+		if fv.IsNil() {
+			return "", false
+		}
+		fv = fv.Elem()
+	}
+	if st := stdTypes[fv.Type()]; st != nil {
+		return st.format(fv.Interface()), true
+	} else if !isTextType(fv.Type()) {
+		return "", false
+	}
+	pv := reflect.New(fv.Type())
+	pv.Elem().Set(fv)
+	switch tv := pv.Interface().(type) {
+	case encoding.TextMarshaler:
+		if data, err := tv.MarshalText(); err == nil {
+			return string(data), true
+		}
+	case fmt.Stringer:
+		return tv.String(), true
+	}
+	return "", false
+}
@@ -0,0 +1,143 @@
+package cfgenv
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/go-andiamo/gopt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+)
+
+type testLevel int
+
+const (
+	testLevelDebug testLevel = iota
+	testLevelInfo
+)
+
+func (l *testLevel) UnmarshalText(text []byte) error {
+	switch strings.ToLower(string(text)) {
+	case "debug":
+		*l = testLevelDebug
+	case "info":
+		*l = testLevelInfo
+	default:
+		return fmt.Errorf("unknown level %q", string(text))
+	}
+	return nil
+}
+
+func (l testLevel) MarshalText() ([]byte, error) {
+	if l == testLevelDebug {
+		return []byte("debug"), nil
+	}
+	return []byte("info"), nil
+}
+
+type testFlagValue struct {
+	value string
+}
+
+func (f *testFlagValue) String() string {
+	return strings.ToLower(f.value)
+}
+
+func (f *testFlagValue) Set(s string) error {
+	if s == "" {
+		return errors.New("empty")
+	}
+	f.value = strings.ToUpper(s)
+	return nil
+}
+
+func TestLoad_TextTypes(t *testing.T) {
+	type config struct {
+		Ip       net.IP
+		Big      *big.Int
+		Level    testLevel
+		LevelPtr *testLevel
+		Levels   []testLevel
+		LevelMap map[string]testLevel
+		KeyMap   map[testLevel]int
+		OptLevel gopt.Optional[testLevel]
+		OptDef   gopt.Optional[testLevel] `env:"default=info"`
+		Flag     testFlagValue
+	}
+	cfg := &config{}
+	err := Load(cfg, MapEnvReader{
+		"IP":        "192.168.0.1",
+		"BIG":       "123456789012345678901234567890",
+		"LEVEL":     "INFO",
+		"LEVEL_PTR": "debug",
+		"LEVELS":    "debug,info",
+		"LEVEL_MAP": "a:debug,b:info",
+		"KEY_MAP":   "debug:1",
+		"OPT_LEVEL": "info",
+		"FLAG":      "foo",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "192.168.0.1", cfg.Ip.String())
+	assert.Equal(t, "123456789012345678901234567890", cfg.Big.String())
+	assert.Equal(t, testLevelInfo, cfg.Level)
+	assert.Equal(t, testLevelDebug, *cfg.LevelPtr)
+	assert.Equal(t, []testLevel{testLevelDebug, testLevelInfo}, cfg.Levels)
+	assert.Equal(t, map[string]testLevel{"a": testLevelDebug, "b": testLevelInfo}, cfg.LevelMap)
+	assert.Equal(t, map[testLevel]int{testLevelDebug: 1}, cfg.KeyMap)
+	assert.True(t, cfg.OptLevel.WasSet())
+	assert.Equal(t, testLevelInfo, cfg.OptLevel.OrElse(testLevelDebug))
+	assert.False(t, cfg.OptDef.WasSet())
+	assert.Equal(t, testLevelInfo, cfg.OptDef.OrElse(testLevelDebug))
+	assert.Equal(t, "FOO", cfg.Flag.value)
+
+	var w bytes.Buffer
+	cfg.LevelMap = nil
+	cfg.KeyMap = nil
+	err = Write(&w, cfg)
+	require.NoError(t, err)
+	const expect = `IP=192.168.0.1
+BIG=123456789012345678901234567890
+LEVEL=info
+LEVEL_PTR=debug
+LEVELS=debug,info
+LEVEL_MAP=
+KEY_MAP=
+OPT_LEVEL=<value>
+OPT_DEF=<value>
+FLAG=foo
+`
+	assert.Equal(t, expect, w.String())
+}
+
+func TestLoad_TextTypes_Errors(t *testing.T) {
+	type config struct {
+		Level    testLevel
+		OptLevel gopt.Optional[testLevel]
+		Flag     *testFlagValue
+		Levels   []testLevel
+	}
+	err := Load(&config{}, MapEnvReader{"LEVEL": "x", "OPT_LEVEL": "y", "FLAG": "", "LEVELS": "info,z"})
+	require.Error(t, err)
+	assert.Equal(t, `env var 'LEVEL' is invalid: unknown level "x"
+env var 'OPT_LEVEL' is invalid: unknown level "y"
+env var 'FLAG' is invalid: empty
+env var 'LEVELS' is invalid: unknown level "z"`, err.Error())
+	var pe *ParseError
+	require.True(t, errors.As(err, &pe))
+	assert.Equal(t, "Level", pe.Field)
+}
+
+func TestExample_TextTypes(t *testing.T) {
+	type config struct {
+		Ip    net.IP
+		Level testLevel `env:"default=info"`
+	}
+	var w bytes.Buffer
+	err := Example(&w, &config{})
+	require.NoError(t, err)
+	assert.Equal(t, "IP=<ip>\nLEVEL=info\n", w.String())
+}
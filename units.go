@@ -0,0 +1,304 @@
+package cfgenv
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// numericUnit is the unit that numeric env var values are parsed with (see env tag 'unit')
+type numericUnit int
+
+const (
+	unitNone numericUnit = iota
+	// unitBytes is byte sizes - e.g. "512KiB", "10MB" or "1.5GiB"
+	unitBytes
+	// unitSI is numbers with SI suffixes - e.g. "2k" or "3M"
+	unitSI
+	// unitPercent is percentages - e.g. "75%" (is 0.75)
+	unitPercent
+)
+
+var numericUnitNames = map[string]numericUnit{
+	"bytes":   unitBytes,
+	"si":      unitSI,
+	"percent": unitPercent,
+}
+
+type unitSuffix struct {
+	suffix     string
+	multiplier *big.Rat
+}
+
+func newUnitSuffix(suffix string, base int64, exp int64) unitSuffix {
+	m := new(big.Int).Exp(big.NewInt(base), big.NewInt(exp), nil)
+	return unitSuffix{suffix: suffix, multiplier: new(big.Rat).SetInt(m)}
+}
+
+// byteSuffixes are the byte size suffixes (in the order preferred when writing) - binary (IEC) suffixes are powers of 1024
+// and decimal suffixes are powers of 1000
+var byteSuffixes = []unitSuffix{
+	newUnitSuffix("EiB", 1024, 6), newUnitSuffix("PiB", 1024, 5), newUnitSuffix("TiB", 1024, 4),
+	newUnitSuffix("GiB", 1024, 3), newUnitSuffix("MiB", 1024, 2), newUnitSuffix("KiB", 1024, 1),
+	newUnitSuffix("EB", 1000, 6), newUnitSuffix("PB", 1000, 5), newUnitSuffix("TB", 1000, 4),
+	newUnitSuffix("GB", 1000, 3), newUnitSuffix("MB", 1000, 2), newUnitSuffix("kB", 1000, 1),
+	newUnitSuffix("B", 1000, 0),
+}
+
+// siSuffixes are the SI suffixes (in the order preferred when writing)
+var siSuffixes = []unitSuffix{
+	newUnitSuffix("E", 1000, 6), newUnitSuffix("P", 1000, 5), newUnitSuffix("T", 1000, 4),
+	newUnitSuffix("G", 1000, 3), newUnitSuffix("M", 1000, 2), newUnitSuffix("k", 1000, 1),
+}
+
+var unitValueRegex = regexp.MustCompile(`^([+-]?(?:\d+(?:\.\d*)?|\.\d+)(?:[eE][+-]?\d+)?)\s*(.*)$`)
+
+var errNotWholeNumber = errors.New("not a whole number")
+
+// desc describes the unit (used in parse error messages)
+func (u numericUnit) desc() string {
+	switch u {
+	case unitBytes:
+		return "a byte size"
+	case unitSI:
+		return "an SI number"
+	case unitPercent:
+		return "a percentage"
+	}
+	return "a number"
+}
+
+// supports determines whether the unit can be used on a field of the type - the numeric type may be a pointer, optional
+// or collection item (percentages are only supported on float types)
+func (u numericUnit) supports(t reflect.Type) bool {
+	leaf, _ := validationTypes(t)
+	if leaf == durationType || isTextType(leaf) {
+		return false
+	}
+	switch leaf.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return u != unitPercent
+	}
+	return false
+}
+
+// supportedDesc describes the field types the unit can be used on (used in tag error messages)
+func (u numericUnit) supportedDesc() string {
+	if u == unitPercent {
+		return "only for float fields"
+	}
+	return "only for numeric fields"
+}
+
+// parse parses a raw value with an optional unit suffix - returning the exact value
+func (u numericUnit) parse(raw string) (*big.Rat, error) {
+	m := unitValueRegex.FindStringSubmatch(strings.TrimSpace(raw))
+	if m == nil {
+		return nil, fmt.Errorf("invalid number '%s'", raw)
+	}
+	r, ok := new(big.Rat).SetString(m[1])
+	if !ok {
+		return nil, fmt.Errorf("invalid number '%s'", raw)
+	}
+	if m[2] == "" {
+		return r, nil
+	}
+	switch u {
+	case unitBytes:
+		for _, s := range byteSuffixes {
+			if strings.EqualFold(m[2], s.suffix) {
+				return r.Mul(r, s.multiplier), nil
+			}
+		}
+	case unitSI:
+		for _, s := range siSuffixes {
+			if m[2] == s.suffix || (s.suffix == "k" && m[2] == "K") {
+				return r.Mul(r, s.multiplier), nil
+			}
+		}
+	case unitPercent:
+		if m[2] == "%" {
+			return r.Quo(r, big.NewRat(100, 1)), nil
+		}
+	}
+	return nil, fmt.Errorf("unknown unit '%s'", m[2])
+}
+
+// parseInt parses a raw value as an int of the bit size - plain ints (including 0x, 0o and 0b prefixed) are parsed as
+// without a unit
+func (u numericUnit) parseInt(raw string, bitSize int) (int64, error) {
+	if i, err := strconv.ParseInt(raw, 0, bitSize); err == nil || u == unitNone {
+		return i, err
+	}
+	r, err := u.parse(raw)
+	if err != nil {
+		return 0, err
+	} else if !r.IsInt() {
+		return 0, errNotWholeNumber
+	}
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(bitSize-1))
+	if n := r.Num(); n.Cmp(limit) >= 0 || n.Cmp(limit.Neg(limit)) < 0 {
+		return 0, &strconv.NumError{Func: "ParseInt", Num: raw, Err: strconv.ErrRange}
+	} else {
+		return n.Int64(), nil
+	}
+}
+
+// parseUint parses a raw value as a uint of the bit size - plain uints (including 0x, 0o and 0b prefixed) are parsed as
+// without a unit
+func (u numericUnit) parseUint(raw string, bitSize int) (uint64, error) {
+	if i, err := strconv.ParseUint(raw, 0, bitSize); err == nil || u == unitNone {
+		return i, err
+	}
+	r, err := u.parse(raw)
+	if err != nil {
+		return 0, err
+	} else if !r.IsInt() {
+		return 0, errNotWholeNumber
+	}
+	if n := r.Num(); n.Sign() < 0 || n.BitLen() > bitSize {
+		return 0, &strconv.NumError{Func: "ParseUint", Num: raw, Err: strconv.ErrRange}
+	} else {
+		return n.Uint64(), nil
+	}
+}
+
+// parseFloat parses a raw value as a float of the bit size
+func (u numericUnit) parseFloat(raw string, bitSize int) (float64, error) {
+	if u == unitNone {
+		return strconv.ParseFloat(raw, bitSize)
+	}
+	r, err := u.parse(raw)
+	if err != nil {
+		return 0, err
+	}
+	f, _ := r.Float64()
+	if math.IsInf(f, 0) || (bitSize == 32 && math.Abs(f) > math.MaxFloat32) {
+		return 0, &strconv.NumError{Func: "ParseFloat", Num: raw, Err: strconv.ErrRange}
+	}
+	return f, nil
+}
+
+// setNumber sets a numeric value (used for gopt.Optional numeric types)
+func (u numericUnit) setNumber(raw string, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := u.parseInt(raw, v.Type().Bits())
+		if err == nil {
+			v.SetInt(i)
+		}
+		return err
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := u.parseUint(raw, v.Type().Bits())
+		if err == nil {
+			v.SetUint(i)
+		}
+		return err
+	}
+	f, err := u.parseFloat(raw, v.Type().Bits())
+	if err == nil {
+		v.SetFloat(f)
+	}
+	return err
+}
+
+// parseError creates the ParseError for a value that could not be parsed with the unit - where msg is the message used
+// when there is no unit
+func (u numericUnit) parseError(name string, fv reflect.Value, err error, msg string) error {
+	if u != unitNone {
+		t := fv.Type()
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if errors.Is(err, strconv.ErrRange) {
+			msg = "overflows " + t.Kind().String()
+		} else if errors.Is(err, errNotWholeNumber) {
+			msg = "is not a whole number"
+		} else {
+			msg = "is not " + u.desc()
+		}
+	}
+	return &ParseError{Name: name, Type: fv.Type(), Err: err, msg: msg}
+}
+
+// format formats a numeric value (or pointer to numeric value) in the most readable unit
+func (u numericUnit) format(v reflect.Value) (string, bool) {
+	if u == unitNone {
+		return "", false
+	} else if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "", false
+		}
+		v = v.Elem()
+	}
+	r := new(big.Rat)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		r.SetInt64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		r.SetUint64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		// the shortest representation avoids binary fractions (e.g. 0.07 rather than 0.07000000000000000666...)
+		if _, ok := r.SetString(strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits())); !ok {
+			// NaN or infinity...
+			return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), true
+		}
+	default:
+		return "", false
+	}
+	switch u {
+	case unitBytes:
+		return readableWithSuffixes(r, byteSuffixes), true
+	case unitSI:
+		return readableWithSuffixes(r, siSuffixes), true
+	case unitPercent:
+		s, _ := exactDecimal(r.Mul(r, big.NewRat(100, 1)), -1)
+		return s + "%", true
+	}
+	return "", false
+}
+
+// readableWithSuffixes returns the shortest exact representation of the value using one of the suffixes (or none)
+func readableWithSuffixes(r *big.Rat, suffixes []unitSuffix) string {
+	result, _ := exactDecimal(r, -1)
+	abs := new(big.Rat).Abs(r)
+	best := ""
+	for _, s := range suffixes {
+		if s.multiplier.Cmp(big.NewRat(1, 1)) == 0 || abs.Cmp(s.multiplier) < 0 {
+			continue
+		}
+		if n, ok := exactDecimal(new(big.Rat).Quo(r, s.multiplier), 3); ok && (best == "" || len(n+s.suffix) < len(best)) {
+			best = n + s.suffix
+		}
+	}
+	if best != "" && len(best) <= len(result) {
+		return best
+	}
+	return result
+}
+
+// exactDecimal returns the decimal string of the value - and false if the value cannot be exactly represented with
+// the maximum number of decimal places (-1 for no maximum, in which case the value is rounded to 12 places)
+func exactDecimal(r *big.Rat, maxPlaces int) (string, bool) {
+	if r.IsInt() {
+		return r.Num().String(), true
+	}
+	places := 12
+	if maxPlaces >= 0 {
+		places = maxPlaces
+		scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(places)), nil)
+		if !new(big.Rat).Mul(r, new(big.Rat).SetInt(scale)).IsInt() {
+			return "", false
+		}
+	}
+	s := strings.TrimRight(r.FloatString(places), "0")
+	return strings.TrimSuffix(s, "."), true
+}
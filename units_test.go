@@ -0,0 +1,281 @@
+package cfgenv
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/go-andiamo/gopt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"testing"
+)
+
+func TestLoad_Units(t *testing.T) {
+	type config struct {
+		CacheSize int64                 `env:"unit=bytes"`
+		BodyLimit uint32                `env:"unit=bytes"`
+		Decimal   int                   `env:"unit=bytes"`
+		Half      int                   `env:"unit=bytes"`
+		Plain     int                   `env:"unit=bytes"`
+		Ratio     float64               `env:"unit=bytes"`
+		Rate      int                   `env:"unit=si"`
+		Big       uint64                `env:"unit=si"`
+		Frac      float32               `env:"unit=si"`
+		Threshold float64               `env:"unit=percent"`
+		Fraction  float32               `env:"unit=percent"`
+		Ptr       *int                  `env:"unit=bytes"`
+		Sizes     []int                 `env:"unit=bytes"`
+		Limits    map[string]uint64     `env:"unit=bytes"`
+		Optional  gopt.Optional[int64]  `env:"unit=bytes"`
+		Default   gopt.Optional[uint16] `env:"unit=si,default=2k"`
+		Hex       int                   `env:"unit=bytes"`
+		Octal     uint16                `env:"unit=si"`
+		Binary    gopt.Optional[int8]   `env:"unit=bytes"`
+	}
+	cfg := &config{}
+	err := Load(cfg, MapEnvReader{
+		"CACHE_SIZE": "512KiB",
+		"BODY_LIMIT": "10MB",
+		"DECIMAL":    "1.5 GiB",
+		"HALF":       "1.5kB",
+		"PLAIN":      "4096",
+		"RATIO":      "0.5KiB",
+		"RATE":       "2k",
+		"BIG":        "3M",
+		"FRAC":       "1.25k",
+		"THRESHOLD":  "75%",
+		"FRACTION":   "0.5",
+		"PTR":        "1kib",
+		"SIZES":      "1KiB,2MiB,100",
+		"LIMITS":     "a:1GB,b:1GiB",
+		"OPTIONAL":   "2TiB",
+		"HEX":        "0x10",
+		"OCTAL":      "0o17",
+		"BINARY":     "0b101",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(512*1024), cfg.CacheSize)
+	assert.Equal(t, uint32(10_000_000), cfg.BodyLimit)
+	assert.Equal(t, 1536*1024*1024, cfg.Decimal)
+	assert.Equal(t, 1500, cfg.Half)
+	assert.Equal(t, 4096, cfg.Plain)
+	assert.Equal(t, 512.0, cfg.Ratio)
+	assert.Equal(t, 2000, cfg.Rate)
+	assert.Equal(t, uint64(3_000_000), cfg.Big)
+	assert.Equal(t, float32(1250), cfg.Frac)
+	assert.Equal(t, 0.75, cfg.Threshold)
+	assert.Equal(t, float32(0.5), cfg.Fraction)
+	assert.Equal(t, 1024, *cfg.Ptr)
+	assert.Equal(t, []int{1024, 2 * 1024 * 1024, 100}, cfg.Sizes)
+	assert.Equal(t, map[string]uint64{"a": 1_000_000_000, "b": 1 << 30}, cfg.Limits)
+	assert.Equal(t, int64(2<<40), cfg.Optional.Default(0))
+	assert.True(t, cfg.Optional.WasSet())
+	assert.Equal(t, uint16(2000), cfg.Default.Default(0))
+	assert.Equal(t, 16, cfg.Hex)
+	assert.Equal(t, uint16(15), cfg.Octal)
+	assert.Equal(t, int8(5), cfg.Binary.Default(0))
+}
+
+func TestLoad_Units_Errors(t *testing.T) {
+	testCases := []struct {
+		cfg         any
+		env         MapEnvReader
+		expectError string
+		expectRange bool
+	}{
+		{
+			cfg: &struct {
+				Test int32 `env:"unit=bytes"`
+			}{},
+			env:         MapEnvReader{"TEST": "2GiB"},
+			expectError: "env var 'TEST' overflows int32",
+			expectRange: true,
+		},
+		{
+			cfg: &struct {
+				Test int32 `env:"unit=bytes"`
+			}{},
+			env: MapEnvReader{"TEST": "1GiB"},
+		},
+		{
+			cfg: &struct {
+				Test int8 `env:"unit=si"`
+			}{},
+			env:         MapEnvReader{"TEST": "-1k"},
+			expectError: "env var 'TEST' overflows int8",
+			expectRange: true,
+		},
+		{
+			cfg: &struct {
+				Test uint64 `env:"unit=bytes"`
+			}{},
+			env:         MapEnvReader{"TEST": "16EiB"},
+			expectError: "env var 'TEST' overflows uint64",
+			expectRange: true,
+		},
+		{
+			cfg: &struct {
+				Test uint `env:"unit=bytes"`
+			}{},
+			env:         MapEnvReader{"TEST": "-1KiB"},
+			expectError: "env var 'TEST' overflows uint",
+			expectRange: true,
+		},
+		{
+			cfg: &struct {
+				Test float32 `env:"unit=si"`
+			}{},
+			env:         MapEnvReader{"TEST": "1e36E"},
+			expectError: "env var 'TEST' overflows float32",
+			expectRange: true,
+		},
+		{
+			cfg: &struct {
+				Test *uint16 `env:"unit=bytes"`
+			}{},
+			env:         MapEnvReader{"TEST": "64KiB"},
+			expectError: "env var 'TEST' overflows uint16",
+			expectRange: true,
+		},
+		{
+			cfg: &struct {
+				Test int `env:"unit=bytes"`
+			}{},
+			env:         MapEnvReader{"TEST": "0.5B"},
+			expectError: "env var 'TEST' is not a whole number",
+		},
+		{
+			cfg: &struct {
+				Test int `env:"unit=bytes"`
+			}{},
+			env:         MapEnvReader{"TEST": "10XB"},
+			expectError: "env var 'TEST' is not a byte size",
+		},
+		{
+			cfg: &struct {
+				Test []int `env:"unit=si"`
+			}{},
+			env:         MapEnvReader{"TEST": "1k,2m"},
+			expectError: "env var 'TEST' is not an SI number",
+		},
+		{
+			cfg: &struct {
+				Test float64 `env:"unit=percent"`
+			}{},
+			env:         MapEnvReader{"TEST": "75 percent"},
+			expectError: "env var 'TEST' is not a percentage",
+		},
+		{
+			cfg: &struct {
+				Test gopt.Optional[int8] `env:"unit=bytes"`
+			}{},
+			env:         MapEnvReader{"TEST": "1KiB"},
+			expectError: "env var 'TEST' is invalid: strconv.ParseInt: parsing \"1KiB\": value out of range",
+			expectRange: true,
+		},
+		{
+			cfg: &struct {
+				Test int `env:"unit=kb"`
+			}{},
+			expectError: "env tag 'unit' on field 'Test' - invalid unit 'kb' (must be bytes, si or percent)",
+		},
+		{
+			cfg: &struct {
+				Test int `env:"unit=percent"`
+			}{},
+			expectError: "cannot use env tag 'unit=percent' on field 'Test' (only for float fields)",
+		},
+		{
+			cfg: &struct {
+				Test string `env:"unit=bytes"`
+			}{},
+			expectError: "cannot use env tag 'unit=bytes' on field 'Test' (only for numeric fields)",
+		},
+		{
+			cfg: &struct {
+				Test []int `env:"unit"`
+			}{},
+			expectError: "cannot use env tag 'unit' without value on field 'Test' (use quotes if necessary)",
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("[%d]", i+1), func(t *testing.T) {
+			err := Load(tc.cfg, tc.env)
+			if tc.expectError == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, tc.expectError, err.Error())
+			assert.Equal(t, tc.expectRange, errors.Is(err, strconv.ErrRange))
+		})
+	}
+}
+
+func TestWrite_Units(t *testing.T) {
+	type config struct {
+		CacheSize int64             `env:"unit=bytes"`
+		BodyLimit uint32            `env:"unit=bytes"`
+		Half      int               `env:"unit=bytes"`
+		Small     int               `env:"unit=bytes"`
+		Odd       int               `env:"unit=bytes"`
+		Negative  int               `env:"unit=bytes"`
+		Ratio     float64           `env:"unit=bytes"`
+		Rate      int               `env:"unit=si"`
+		Frac      float32           `env:"unit=si"`
+		Threshold float64           `env:"unit=percent"`
+		Small2    float32           `env:"unit=percent"`
+		Ptr       *int              `env:"unit=bytes"`
+		Sizes     []int             `env:"unit=bytes"`
+		Limits    map[string]uint64 `env:"unit=bytes,format=csv"`
+	}
+	p := 1 << 20
+	cfg := &config{
+		CacheSize: 512 * 1024,
+		BodyLimit: 10_000_000,
+		Half:      1536 * 1024 * 1024,
+		Small:     100,
+		Odd:       1234567,
+		Negative:  -2048,
+		Ratio:     1536 * 1024,
+		Rate:      2000,
+		Frac:      1_250_000,
+		Threshold: 0.75,
+		Small2:    0.07,
+		Ptr:       &p,
+		Sizes:     []int{1024, 1000, 1},
+		Limits:    map[string]uint64{"a": 1 << 30},
+	}
+	var w bytes.Buffer
+	err := Write(&w, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, `CACHE_SIZE=512KiB
+BODY_LIMIT=10MB
+HALF=1.5GiB
+SMALL=100
+ODD=1234567
+NEGATIVE=-2KiB
+RATIO=1.5MiB
+RATE=2k
+FRAC=1.25M
+THRESHOLD=75%
+SMALL2=7%
+PTR=1MiB
+SIZES=1KiB,1kB,1
+LIMITS=a:1GiB
+`, w.String())
+
+	loaded := &config{}
+	err = Load(loaded, NewEnvFileReader(&w, nil))
+	require.NoError(t, err)
+	assert.Equal(t, cfg, loaded)
+
+	w.Reset()
+	err = Example(&w, &struct {
+		Size      int     `env:"unit=bytes"`
+		Threshold float64 `env:"unit=percent"`
+	}{})
+	require.NoError(t, err)
+	assert.Equal(t, "SIZE=0\nTHRESHOLD=0%\n", w.String())
+}
@@ -0,0 +1,72 @@
+package cfgenv
+
+import "reflect"
+
+// Unsetter is an optional interface that an EnvReader can implement
+// to support unsetting env vars after loading (see env tag `unset` and UnsetSecretsOption)
+//
+// The EnvReader returned by NewEnvReader unsets env vars from the process (using os.Unsetenv) - so that child processes
+// (e.g. started with os/exec) do not inherit them. For readers that do not implement Unsetter, unsetting is a no-op
+type Unsetter interface {
+	// Unsetenv see os.Unsetenv
+	Unsetenv(key string) error
+}
+
+// UnsetSecretsOption is an option that can be passed to Load or LoadAs
+// and determines whether the env vars of all secret fields (see Secret, env tag `secret` and SecretNamesOption)
+// are unset after loading - as if they had the `unset` env tag
+type UnsetSecretsOption interface {
+	// UnsetSecrets returns whether the env vars of secret fields are unset after loading
+	UnsetSecrets() bool
+}
+
+type unsetSecretsOpt struct{}
+
+func (u *unsetSecretsOpt) UnsetSecrets() bool {
+	return true
+}
+
+// NewUnsetSecrets creates a new UnsetSecretsOption - where the env vars of all secret fields are unset after loading
+func NewUnsetSecrets() UnsetSecretsOption {
+	return &unsetSecretsOpt{}
+}
+
+// consume records the env var names of a loaded field (including any deprecated and file suffixed names) to be unset
+// after loading - where the field has the `unset` env tag or is a secret and an UnsetSecretsOption is passed
+func (o *opts) consume(name string, prefix string, fld reflect.StructField, fi *fieldInfo) {
+	if !o.unsets(name, fi) {
+		return
+	}
+	*o.consumed = append(*o.consumed, name)
+	for _, alias := range fi.aliases {
+		*o.consumed = append(*o.consumed, o.naming.BuildName(prefix, o.separator.GetSeparator(), fld, alias))
+	}
+	if o.fileVars != nil && !fi.file {
+		*o.consumed = append(*o.consumed, name+o.fileVars.FileSuffix())
+	}
+}
+
+// consumeItem records the env var name of an indexed item (e.g. TOKENS_0) of a loaded field to be unset after loading
+func (o *opts) consumeItem(name string, itemName string, fi *fieldInfo) {
+	if o.unsets(name, fi) {
+		*o.consumed = append(*o.consumed, itemName)
+	}
+}
+
+// unsets determines whether the env vars of a loaded field are to be unset after loading
+func (o *opts) unsets(name string, fi *fieldInfo) bool {
+	return o.consumed != nil && (fi.unset || (o.unsetSecrets && o.isSecret(name, fi)))
+}
+
+// unsetConsumed unsets the consumed env var names (if the reader is an Unsetter) - only called once the config has been
+// loaded without errors (so that, on failure, the env vars are still available to a retry or fallback)
+func (o *opts) unsetConsumed() error {
+	if u, ok := o.reader.(Unsetter); ok && o.consumed != nil {
+		for _, name := range *o.consumed {
+			if err := u.Unsetenv(name); err != nil {
+				return &UnsetError{Name: name, Err: err}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package cfgenv
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+)
+
+func TestLoad_Unset(t *testing.T) {
+	type config struct {
+		Host     string
+		Password string `env:"unset,alias=DB_PASSWORD"`
+		Token    string `env:"unset,optional"`
+		Port     int    `env:"unset,optional,default=8080"`
+	}
+	env := MapEnvReader{"HOST": "localhost", "PASSWORD": "foo", "DB_PASSWORD": "bar", "OTHER": "baz"}
+	cfg := &config{}
+	err := Load(cfg, &mapUnsetter{env})
+	require.NoError(t, err)
+	assert.Equal(t, "foo", cfg.Password)
+	assert.Equal(t, 8080, cfg.Port)
+	assert.Equal(t, MapEnvReader{"HOST": "localhost", "OTHER": "baz"}, env)
+
+	// MapEnvReader is not an Unsetter (the map is never modified)...
+	env = MapEnvReader{"HOST": "localhost", "PASSWORD": "foo"}
+	err = Load(&config{}, env)
+	require.NoError(t, err)
+	assert.Equal(t, MapEnvReader{"HOST": "localhost", "PASSWORD": "foo"}, env)
+
+	err = Load(&struct {
+		Test map[string]string `env:"unset,prefix=TEST_"`
+	}{}, MapEnvReader{})
+	require.Error(t, err)
+	assert.Equal(t, "cannot use env tags 'alias', 'file' or 'unset' on field 'Test' (only for fields loaded from a single env var)", err.Error())
+}
+
+func TestLoad_Unset_Indexed(t *testing.T) {
+	type config struct {
+		Tokens   []string `env:"unset"`
+		ApiKeys  Secret[[]string]
+		Hosts    []string
+		Password string `env:"unset"`
+	}
+	m := MapEnvReader{
+		"TOKENS_0": "a", "TOKENS_1": "b",
+		"API_KEYS_0": "c", "API_KEYS_1": "d",
+		"HOSTS_0":  "e",
+		"PASSWORD": "f",
+	}
+	cfg := &config{}
+	err := Load(cfg, NewUnsetSecrets(), &mapUnsetter{m})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, cfg.Tokens)
+	assert.Equal(t, []string{"c", "d"}, cfg.ApiKeys.Reveal())
+	assert.Equal(t, MapEnvReader{"HOSTS_0": "e"}, m)
+}
+
+func TestLoad_Unset_OsEnv(t *testing.T) {
+	type config struct {
+		Password string `env:"unset"`
+		Host     string
+	}
+	t.Setenv("TEST_UNSET_PASSWORD", "foo")
+	t.Setenv("TEST_UNSET_HOST", "localhost")
+	cfg := &config{}
+	err := Load(cfg, NewPrefix("TEST_UNSET"))
+	require.NoError(t, err)
+	assert.Equal(t, "foo", cfg.Password)
+	_, ok := os.LookupEnv("TEST_UNSET_PASSWORD")
+	assert.False(t, ok)
+	_, ok = os.LookupEnv("TEST_UNSET_HOST")
+	assert.True(t, ok)
+}
+
+func TestLoad_UnsetSecrets(t *testing.T) {
+	type config struct {
+		Host     string
+		Password string `env:"secret"`
+		ApiKey   Secret[string]
+		Token    string
+		Missing  string `env:"secret,optional"`
+	}
+	env := MapEnvReader{"HOST": "localhost", "PASSWORD": "foo", "API_KEY": "bar", "TOKEN_FILE": writeTestFile(t, "token", "baz")}
+	cfg := &config{}
+	err := Load(cfg, NewUnsetSecrets(), NewSecretNames(), NewFileVars(true), &mapUnsetter{env})
+	require.NoError(t, err)
+	assert.Equal(t, "foo", cfg.Password)
+	assert.Equal(t, "bar", cfg.ApiKey.Reveal())
+	assert.Equal(t, "baz", cfg.Token)
+	assert.Equal(t, MapEnvReader{"HOST": "localhost"}, env)
+
+	env = MapEnvReader{"HOST": "localhost", "PASSWORD": "foo", "API_KEY": "bar", "TOKEN": "baz"}
+	err = Load(cfg, &mapUnsetter{env})
+	require.NoError(t, err)
+	assert.Len(t, env, 4)
+
+	err = Load(cfg, NewUnsetSecrets(), NewUnsetSecrets())
+	require.Error(t, err)
+	assert.Equal(t, "multiple unset secrets options", err.Error())
+}
+
+func TestLoad_Unset_MultiReader(t *testing.T) {
+	type config struct {
+		Password string `env:"unset"`
+	}
+	m1 := MapEnvReader{"PASSWORD": "foo"}
+	m2 := MapEnvReader{"PASSWORD": "bar"}
+	err := Load(&config{}, NewMultiEnvReader(&mapUnsetter{m1}, MapEnvReader{"PASSWORD": "baz"}, &mapUnsetter{m2}))
+	require.NoError(t, err)
+	assert.Empty(t, m1)
+	assert.Empty(t, m2)
+
+	// no-op for readers that are not Unsetter...
+	nu := &nonUnsetter{m: MapEnvReader{"PASSWORD": "foo"}}
+	err = Load(&config{}, nu)
+	require.NoError(t, err)
+	assert.Len(t, nu.m, 1)
+}
+
+func TestLoad_Unset_Error(t *testing.T) {
+	type config struct {
+		Password string `env:"unset"`
+		Port     int
+	}
+	reader := &failingUnsetter{MapEnvReader{"PASSWORD": "foo", "PORT": "80"}}
+	err := Load(&config{}, reader)
+	require.Error(t, err)
+	assert.Equal(t, "unable to unset env var 'PASSWORD': unset failed", err.Error())
+	var ue *UnsetError
+	require.True(t, errors.As(err, &ue))
+	assert.Equal(t, "PASSWORD", ue.Name)
+
+	// LoadAs still returns the loaded config...
+	cfg, err := LoadAs[config](reader)
+	require.Error(t, err)
+	require.True(t, errors.As(err, &ue))
+	require.NotNil(t, cfg)
+	assert.Equal(t, "foo", cfg.Password)
+	assert.Equal(t, 80, cfg.Port)
+
+	// nothing is unset on a load error...
+	m := MapEnvReader{"PASSWORD": "foo", "PORT": "x"}
+	err = Load(&config{}, &mapUnsetter{m})
+	require.Error(t, err)
+	assert.Equal(t, "env var 'PORT' is not an int", err.Error())
+	assert.Equal(t, MapEnvReader{"PASSWORD": "foo", "PORT": "x"}, m)
+	cfg, err = LoadAs[config](&mapUnsetter{m})
+	require.Error(t, err)
+	assert.Nil(t, cfg)
+	assert.Len(t, m, 2)
+}
+
+type nonUnsetter struct {
+	m MapEnvReader
+}
+
+func (n *nonUnsetter) LookupEnv(key string) (string, bool) {
+	return n.m.LookupEnv(key)
+}
+
+func (n *nonUnsetter) Environ() []string {
+	return n.m.Environ()
+}
+
+type mapUnsetter struct {
+	MapEnvReader
+}
+
+func (m *mapUnsetter) Unsetenv(key string) error {
+	delete(m.MapEnvReader, key)
+	return nil
+}
+
+type failingUnsetter struct {
+	MapEnvReader
+}
+
+func (f *failingUnsetter) Unsetenv(key string) error {
+	return errors.New("unset failed")
+}
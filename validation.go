@@ -0,0 +1,244 @@
+package cfgenv
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// validate checks the field value fv against all the validation tags on the field
+func (fi *fieldInfo) validate(name string, fv reflect.Value) error {
+	for _, vld := range fi.validations {
+		if err := vld.validate(name, fv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type validation struct {
+	token   string
+	arg     string
+	length  int
+	options []string
+	regex   *regexp.Regexp
+}
+
+func newValidation(fld reflect.StructField, token string, arg string) (*validation, error) {
+	result := &validation{
+		token: token,
+		arg:   arg,
+	}
+	leaf, collection := validationTypes(fld.Type)
+	var err error
+	switch token {
+	case tokenMin, tokenMax:
+		if _, err = compareNumber(reflect.New(leaf).Elem(), arg); err != nil {
+			return nil, newTagError(fld, token+"="+arg, err, "env tag '%s' on field '%s' - %s", token, fld.Name, err.Error())
+		}
+	case tokenMinLen, tokenMaxLen:
+		if !collection && leaf.Kind() != reflect.String {
+			return nil, newTagError(fld, token+"="+arg, nil, "cannot use env tag '%s' on field '%s' (only for strings, slices or maps)", token, fld.Name)
+		} else if result.length, err = strconv.Atoi(arg); err != nil || result.length < 0 {
+			return nil, newTagError(fld, token+"="+arg, err, "env tag '%s' on field '%s' - invalid length '%s'", token, fld.Name, arg)
+		}
+	case tokenOneOf:
+		result.options = strings.Split(arg, "|")
+	case tokenPattern:
+		if result.regex, err = regexp.Compile(arg); err != nil {
+			return nil, newTagError(fld, token+"="+arg, err, "env tag '%s' on field '%s' - invalid regexp: %s", token, fld.Name, err.Error())
+		}
+	}
+	return result, nil
+}
+
+func (vld *validation) rule() string {
+	if vld.token == tokenNotEmpty {
+		return vld.token
+	}
+	return vld.token + "=" + vld.arg
+}
+
+// validate checks the (already set) field value fv
+//
+// pointers and optionals are unwrapped (and not checked if nil or not present), min, max, oneof & pattern are checked
+// against each item of slices and maps, whereas minlen, maxlen & notempty are checked against the number of items
+func (vld *validation) validate(name string, fv reflect.Value) error {
+	v, ok := validationValue(fv)
+	if !ok {
+		return nil
+	}
+	isCollection := v.Kind() == reflect.Slice || v.Kind() == reflect.Array || v.Kind() == reflect.Map
+	var reason string
+	switch {
+	case v.Kind() == reflect.Array && vld.token == tokenNotEmpty:
+		if v.IsZero() {
+			reason = "value is empty"
+		}
+	case isCollection && (vld.token == tokenMinLen || vld.token == tokenMaxLen || vld.token == tokenNotEmpty):
+		reason = vld.checkLength(v.Len(), "item count")
+	case v.Kind() == reflect.Slice || v.Kind() == reflect.Array:
+		for i := 0; i < v.Len() && reason == ""; i++ {
+			if iv, ok := validationValue(v.Index(i)); ok {
+				reason = vld.checkValue(iv)
+			}
+		}
+	case v.Kind() == reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() && reason == "" {
+			if iv, ok := validationValue(iter.Value()); ok {
+				reason = vld.checkValue(iv)
+			}
+		}
+	default:
+		reason = vld.checkValue(v)
+	}
+	if reason != "" {
+		return &ValidationError{
+			Name: name,
+			Type: fv.Type(),
+			Rule: vld.rule(),
+			msg:  reason,
+		}
+	}
+	return nil
+}
+
+func (vld *validation) checkValue(v reflect.Value) string {
+	switch vld.token {
+	case tokenMin, tokenMax:
+		if c, err := compareNumber(v, vld.arg); err != nil {
+			return err.Error()
+		} else if c < 0 && vld.token == tokenMin {
+			return fmt.Sprintf("value %s is less than %s", valueString(v), vld.arg)
+		} else if c > 0 && vld.token == tokenMax {
+			return fmt.Sprintf("value %s is greater than %s", valueString(v), vld.arg)
+		}
+	case tokenMinLen, tokenMaxLen:
+		return vld.checkLength(utf8.RuneCountInString(v.String()), "length")
+	case tokenNotEmpty:
+		if v.IsZero() {
+			return "value is empty"
+		}
+	case tokenOneOf:
+		s := valueString(v)
+		for _, o := range vld.options {
+			if s == o {
+				return ""
+			}
+		}
+		return fmt.Sprintf("value '%s' is not one of '%s'", s, vld.arg)
+	case tokenPattern:
+		if s := valueString(v); !vld.regex.MatchString(s) {
+			return fmt.Sprintf("value '%s' does not match pattern '%s'", s, vld.arg)
+		}
+	}
+	return ""
+}
+
+func (vld *validation) checkLength(l int, what string) string {
+	switch {
+	case vld.token == tokenNotEmpty && l == 0:
+		return "value is empty"
+	case vld.token == tokenMinLen && l < vld.length:
+		return fmt.Sprintf("%s %d is less than %d", what, l, vld.length)
+	case vld.token == tokenMaxLen && l > vld.length:
+		return fmt.Sprintf("%s %d is greater than %d", what, l, vld.length)
+	}
+	return ""
+}
+
+// compareNumber compares numeric value v with the (string) arg - returning -1, 0 or 1
+func compareNumber(v reflect.Value, arg string) (int, error) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		a, err := strconv.ParseInt(arg, 0, 64)
+		if err != nil && v.Type() == durationType {
+			var d time.Duration
+			d, err = time.ParseDuration(arg)
+			a = int64(d)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("invalid number '%s'", arg)
+		}
+		return compare(v.Int(), a), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		a, err := strconv.ParseUint(arg, 0, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number '%s'", arg)
+		}
+		return compare(v.Uint(), a), nil
+	case reflect.Float32, reflect.Float64:
+		a, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number '%s'", arg)
+		}
+		return compare(v.Float(), a), nil
+	}
+	return 0, errors.New("only for numeric types")
+}
+
+func compare[T int64 | uint64 | float64](v T, arg T) int {
+	if v < arg {
+		return -1
+	} else if v > arg {
+		return 1
+	}
+	return 0
+}
+
+func valueString(v reflect.Value) string {
+	if s, ok := textValue(v); ok {
+		return s
+	} else if v.Kind() == reflect.String {
+		return v.String()
+	}
+	return fmt.Sprintf("%v", v.Interface())
+}
+
+// validationValue unwraps pointers and optionals - returning false if there is no value
+func validationValue(v reflect.Value) (reflect.Value, bool) {
+	for {
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return v, false
+			}
+			v = v.Elem()
+		} else if isOptionalType(v.Type()) {
+			ov := reflect.New(v.Type())
+			ov.Elem().Set(v)
+			res := ov.MethodByName("GetOk").Call(nil)
+			if !res[1].Bool() {
+				return v, false
+			}
+			v = res[0]
+		} else {
+			return v, true
+		}
+	}
+}
+
+// validationTypes returns the leaf type that item validations are applied to and whether the type is a collection
+func validationTypes(t reflect.Type) (reflect.Type, bool) {
+	collection := false
+	for {
+		switch {
+		case t.Kind() == reflect.Pointer:
+			t = t.Elem()
+		case isOptionalType(t):
+			t = optionalItemType(t)
+		case isTextType(t):
+			return t, collection
+		case t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map:
+			collection = true
+			t = t.Elem()
+		default:
+			return t, collection
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package cfgenv
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-andiamo/gopt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestLoad_Validations(t *testing.T) {
+	testCases := []struct {
+		cfg         any
+		env         map[string]string
+		expectError string
+	}{
+		{
+			cfg: &struct {
+				Test int `env:"min=1,max=10"`
+			}{},
+			env: map[string]string{"TEST": "5"},
+		},
+		{
+			cfg: &struct {
+				Test int `env:"min=1,max=10"`
+			}{},
+			env:         map[string]string{"TEST": "0"},
+			expectError: "env var 'TEST' failed validation 'min=1' - value 0 is less than 1",
+		},
+		{
+			cfg: &struct {
+				Test int `env:"min=1,max=10"`
+			}{},
+			env:         map[string]string{"TEST": "11"},
+			expectError: "env var 'TEST' failed validation 'max=10' - value 11 is greater than 10",
+		},
+		{
+			cfg: &struct {
+				Test int `env:"optional,min=1"`
+			}{},
+			env: map[string]string{"TEST": "1"},
+		},
+		{
+			cfg: &struct {
+				Test int `env:"optional,default=0,min=1"`
+			}{},
+			expectError: "env var 'TEST' failed validation 'min=1' - value 0 is less than 1",
+		},
+		{
+			cfg: &struct {
+				Test uint `env:"max=0x10"`
+			}{},
+			env:         map[string]string{"TEST": "17"},
+			expectError: "env var 'TEST' failed validation 'max=0x10' - value 17 is greater than 0x10",
+		},
+		{
+			cfg: &struct {
+				Test float64 `env:"min=0.5"`
+			}{},
+			env:         map[string]string{"TEST": "0.25"},
+			expectError: "env var 'TEST' failed validation 'min=0.5' - value 0.25 is less than 0.5",
+		},
+		{
+			cfg: &struct {
+				Test time.Duration `env:"min=1s"`
+			}{},
+			env:         map[string]string{"TEST": "1µs"},
+			expectError: "env var 'TEST' failed validation 'min=1s' - value 1µs is less than 1s",
+		},
+		{
+			cfg: &struct {
+				Test *int `env:"min=1"`
+			}{},
+		},
+		{
+			cfg: &struct {
+				Test *int `env:"min=1"`
+			}{},
+			env:         map[string]string{"TEST": "0"},
+			expectError: "env var 'TEST' failed validation 'min=1' - value 0 is less than 1",
+		},
+		{
+			cfg: &struct {
+				Test string `env:"oneof='a|b|c'"`
+			}{},
+			env: map[string]string{"TEST": "b"},
+		},
+		{
+			cfg: &struct {
+				Test string `env:"oneof='a|b|c'"`
+			}{},
+			env:         map[string]string{"TEST": "d"},
+			expectError: "env var 'TEST' failed validation 'oneof=a|b|c' - value 'd' is not one of 'a|b|c'",
+		},
+		{
+			cfg: &struct {
+				Test string `env:"pattern='^[a-z]+$'"`
+			}{},
+			env:         map[string]string{"TEST": "Abc"},
+			expectError: "env var 'TEST' failed validation 'pattern=^[a-z]+$' - value 'Abc' does not match pattern '^[a-z]+$'",
+		},
+		{
+			cfg: &struct {
+				Test string `env:"minlen=2,maxlen=3"`
+			}{},
+			env:         map[string]string{"TEST": "a"},
+			expectError: "env var 'TEST' failed validation 'minlen=2' - length 1 is less than 2",
+		},
+		{
+			cfg: &struct {
+				Test string `env:"minlen=2,maxlen=3"`
+			}{},
+			env:         map[string]string{"TEST": "abcd"},
+			expectError: "env var 'TEST' failed validation 'maxlen=3' - length 4 is greater than 3",
+		},
+		{
+			cfg: &struct {
+				Test string `env:"notempty"`
+			}{},
+			env:         map[string]string{"TEST": ""},
+			expectError: "env var 'TEST' failed validation 'notempty' - value is empty",
+		},
+		{
+			cfg: &struct {
+				Test []int `env:"min=1,maxlen=2"`
+			}{},
+			env:         map[string]string{"TEST": "1,0"},
+			expectError: "env var 'TEST' failed validation 'min=1' - value 0 is less than 1",
+		},
+		{
+			cfg: &struct {
+				Test []int `env:"min=1,maxlen=2"`
+			}{},
+			env:         map[string]string{"TEST": "1,2,3"},
+			expectError: "env var 'TEST' failed validation 'maxlen=2' - item count 3 is greater than 2",
+		},
+		{
+			cfg: &struct {
+				Test []string `env:"notempty"`
+			}{},
+			env:         map[string]string{"TEST": ""},
+			expectError: "env var 'TEST' failed validation 'notempty' - value is empty",
+		},
+		{
+			cfg: &struct {
+				Test map[string]int `env:"max=5"`
+			}{},
+			env:         map[string]string{"TEST": "a:1,b:6"},
+			expectError: "env var 'TEST' failed validation 'max=5' - value 6 is greater than 5",
+		},
+		{
+			cfg: &struct {
+				Test map[string]string `env:"prefix=SUB_,minlen=1"`
+			}{},
+			expectError: "env var 'TEST' failed validation 'minlen=1' - item count 0 is less than 1",
+		},
+		{
+			cfg: &struct {
+				Test gopt.Optional[int] `env:"max=5"`
+			}{},
+		},
+		{
+			cfg: &struct {
+				Test gopt.Optional[int] `env:"max=5"`
+			}{},
+			env:         map[string]string{"TEST": "6"},
+			expectError: "env var 'TEST' failed validation 'max=5' - value 6 is greater than 5",
+		},
+		{
+			cfg: &struct {
+				Test string `env:"min=1"`
+			}{},
+			expectError: "env tag 'min' on field 'Test' - only for numeric types",
+		},
+		{
+			cfg: &struct {
+				Test int `env:"max=x"`
+			}{},
+			expectError: "env tag 'max' on field 'Test' - invalid number 'x'",
+		},
+		{
+			cfg: &struct {
+				Test int `env:"minlen=1"`
+			}{},
+			expectError: "cannot use env tag 'minlen' on field 'Test' (only for strings, slices or maps)",
+		},
+		{
+			cfg: &struct {
+				Test string `env:"maxlen=x"`
+			}{},
+			expectError: "env tag 'maxlen' on field 'Test' - invalid length 'x'",
+		},
+		{
+			cfg: &struct {
+				Test string `env:"pattern='['"`
+			}{},
+			expectError: "env tag 'pattern' on field 'Test' - invalid regexp: error parsing regexp: missing closing ]: `[`",
+		},
+		{
+			cfg: &struct {
+				Test string `env:"oneof"`
+			}{},
+			expectError: "cannot use env tag 'oneof' without value on field 'Test' (use quotes if necessary)",
+		},
+		{
+			cfg: &struct {
+				Test struct{} `env:"notempty"`
+			}{},
+			expectError: "cannot use validation env tags on struct field 'Test'",
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("[%d]", i+1), func(t *testing.T) {
+			err := Load(tc.cfg, MapEnvReader(tc.env))
+			if tc.expectError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectError, err.Error())
+			}
+		})
+	}
+}
+
+func TestLoad_ValidationError(t *testing.T) {
+	type config struct {
+		Inner struct {
+			Port int `env:"max=65535"`
+		} `env:"prefix=DB"`
+	}
+	err := Load(&config{}, MapEnvReader{"DB_PORT": "65536"})
+	require.Error(t, err)
+	var ve *ValidationError
+	require.True(t, errors.As(err, &ve))
+	assert.Equal(t, "DB_PORT", ve.Name)
+	assert.Equal(t, "Inner.Port", ve.Field)
+	assert.Equal(t, "max=65535", ve.Rule)
+	assert.Nil(t, ve.Unwrap())
+}
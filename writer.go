@@ -5,14 +5,20 @@ import (
 	"fmt"
 	"io"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Write writes the current config
 //
 // the type of T must be a struct
 //
+// By default, env vars are written as KEY=value lines (see NewEnvFileFormatter) - where a value containing a newline
+// (e.g. a field with `format=lines`) cannot be written and is an error (use another Formatter, e.g. NewDotenvFormatter,
+// to write multi-line values)
+//
 // Use any options (such as PrefixOption, SeparatorOption, NamingOption or multiple CustomSetterOption) to alter
 // loading behaviour
 func Write(w io.Writer, cfg any, options ...any) error {
@@ -29,7 +35,11 @@ func Write(w io.Writer, cfg any, options ...any) error {
 			return errors.New("cfg not a struct")
 		}
 	}
-	return write(w, v, o.prefix.GetPrefix(), true, o)
+	vars := make([]EnvVar, 0)
+	if err = write(&vars, v, o.prefix.GetPrefix(), true, o); err != nil {
+		return err
+	}
+	return o.formatter.Format(w, vars)
 }
 
 // ExampleOf writes an example of the specified T config
@@ -63,45 +73,58 @@ func Example(w io.Writer, cfg any, options ...any) error {
 			return errors.New("cfg not a struct")
 		}
 	}
-	return write(w, v, o.prefix.GetPrefix(), false, o)
+	vars := make([]EnvVar, 0)
+	if err = write(&vars, v, o.prefix.GetPrefix(), false, o); err != nil {
+		return err
+	}
+	return o.formatter.Format(w, vars)
 }
 
-func write(w io.Writer, v reflect.Value, prefix string, actual bool, options *opts) error {
+// write collects the env vars of a struct (in field order) - a single traversal, whatever the Formatter used
+func write(out *[]EnvVar, v reflect.Value, prefix string, actual bool, options *opts) error {
 	seen := map[string]bool{}
-	added := map[string]string{}
-	err := writeValue(w, v, prefix, actual, options, seen, added)
+	added := map[string]EnvVar{}
+	err := writeValue(out, v, prefix, actual, options, seen, added)
 	if err != nil {
 		return err
 	}
-	for k, v := range added {
+	keys := make([]string, 0, len(added))
+	for k := range added {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
 		if !seen[k] {
 			seen[k] = true
-			if _, err = w.Write([]byte(k + "=" + v + "\n")); err != nil {
-				return err
-			}
+			*out = append(*out, added[k])
 		}
 	}
-	return err
+	return nil
 }
 
-func writeValue(w io.Writer, v reflect.Value, prefix string, actual bool, options *opts, seen map[string]bool, added map[string]string) error {
+func writeValue(out *[]EnvVar, v reflect.Value, prefix string, actual bool, options *opts, seen map[string]bool, added map[string]EnvVar) error {
 	t := v.Type()
 	for f := 0; f < t.NumField(); f++ {
-		if fld := t.Field(f); fld.Anonymous {
+		if fld := t.Field(f); isIgnoredField(fld) {
+			continue
+		} else if fld.Anonymous {
 			ev := v.Field(f)
-			if err := writeValue(w, ev, prefix, actual, options, seen, added); err != nil {
+			if err := writeValue(out, ev, prefix, actual, options, seen, added); err != nil {
 				return err
 			}
 		} else if fld.IsExported() {
+			fld, fv, wrapped := unwrapSecret(fld, v.Field(f))
 			fi, err := getFieldInfo(fld, options)
+			if err == nil && wrapped {
+				err = fi.wrapSecret(fld)
+			}
 			if err != nil {
-				return err
+				return withFieldPath(err, fld.Name, options.naming.BuildName(prefix, options.separator.GetSeparator(), fld, tagName(fld)))
 			}
 			name := options.naming.BuildName(prefix, options.separator.GetSeparator(), fld, fi.name)
 			if !seen[name] {
 				seen[name] = true
 				if fi.isStruct {
-					fv := v.Field(f)
 					if fi.pointer {
 						if fv.IsNil() && actual {
 							continue
@@ -112,21 +135,34 @@ func writeValue(w io.Writer, v reflect.Value, prefix string, actual bool, option
 						}
 					}
 					pfx := addPrefixes(prefix, fi.prefix, options.separator.GetSeparator())
-					if err = write(w, fv, pfx, actual, options); err != nil {
+					if err = write(out, fv, pfx, actual, options); err != nil {
 						return err
 					}
-				} else if !actual {
-					if !fi.isPrefixedMap {
-						if err = writeExampleValue(w, name, v.Field(f), fi); err != nil {
+				} else if fi.isStructMap {
+					pfx := addPrefixes(prefix, fi.prefix, options.separator.GetSeparator())
+					if err = writeStructMap(out, fv, pfx, actual, options); err != nil {
+						return err
+					}
+				} else if fi.isStructSlice {
+					pfx := structSlicePrefix(name, prefix, fi, options)
+					if err = writeStructSlice(out, fv, pfx, actual, options); err != nil {
+						return err
+					}
+				} else if fi.isPrefixedMap || fi.isMatchedMap {
+					if actual {
+						pfx := ""
+						if fi.isPrefixedMap {
+							pfx = addPrefixes(prefix, fi.prefix, options.separator.GetSeparator())
+						}
+						if err = writeEnvMap(fv, pfx, fld, fi, options, added); err != nil {
 							return err
 						}
 					}
-				} else if fi.isPrefixedMap {
-					m := v.Field(f).Interface().(map[string]string)
-					for k, v := range m {
-						added[k] = v
+				} else if !actual {
+					if err = writeExampleValue(out, name, fv, fi, options); err != nil {
+						return err
 					}
-				} else if err = writeActualValue(w, name, v.Field(f), fi); err != nil {
+				} else if err = writeActualValue(out, name, fv, fi, options); err != nil {
 					return err
 				}
 			}
@@ -135,75 +171,261 @@ func writeValue(w io.Writer, v reflect.Value, prefix string, actual bool, option
 	return nil
 }
 
-func writeExampleValue(w io.Writer, name string, fv reflect.Value, fi *fieldInfo) error {
+// writeStructSlice writes the items of a slice of structs as indexed env vars (for an example, a single item is written)
+func writeStructSlice(out *[]EnvVar, fv reflect.Value, prefix string, actual bool, options *opts) error {
+	sep := options.separator.GetSeparator()
+	if !actual {
+		it := fv.Type().Elem()
+		if it.Kind() == reflect.Pointer {
+			it = it.Elem()
+		}
+		return write(out, reflect.New(it).Elem(), prefix+sep+"0", actual, options)
+	}
+	for i := 0; i < fv.Len(); i++ {
+		iv := fv.Index(i)
+		if iv.Kind() == reflect.Pointer {
+			if iv.IsNil() {
+				continue
+			}
+			iv = iv.Elem()
+		}
+		if err := write(out, iv, prefix+sep+strconv.Itoa(i), actual, options); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeEnvMap adds the items of a prefix and/or match map as env vars - named by the prefix and map key
+//
+// where the map keys are transformed (see fieldInfo.hasEnvMapNames) the env var names cannot be determined - so writing
+// a non-empty map is an error (rather than silently losing the items)
+func writeEnvMap(fv reflect.Value, prefix string, fld reflect.StructField, fi *fieldInfo, options *opts, added map[string]EnvVar) error {
+	if fv.Len() > 0 && !fi.hasEnvMapNames() {
+		return &UnsupportedTypeError{
+			Field: fld.Name,
+			Type:  fld.Type,
+			msg:   fmt.Sprintf("cannot write map field '%s' - env var names cannot be determined from transformed keys (env tags '%s', '%s' or a '%s' capture group)", fld.Name, tokenKeyTrim, tokenKeyCase, tokenMatch),
+		}
+	}
+	for _, mk := range fv.MapKeys() {
+		if mv := fv.MapIndex(mk); mv.Kind() != reflect.Pointer || !mv.IsNil() {
+			k := prefix + itemString(mk)
+			value, err := actualValueString(reflect.Indirect(mv), fi, 0)
+			if err != nil {
+				return err
+			}
+			ev := EnvVar{Name: k, Value: value, Secret: options.isSecret(k, fi)}
+			if ev.Secret {
+				ev.Value = options.redact(ev.Value)
+			}
+			added[k] = ev
+		}
+	}
+	return nil
+}
+
+func writeExampleValue(out *[]EnvVar, name string, fv reflect.Value, fi *fieldInfo, options *opts) error {
 	eg := "<value>"
-	if fi.hasDefault {
+	secret := options.isSecret(name, fi)
+	if fi.file {
+		// the env var is the path of the file (not the value)...
+		eg = "<file path>"
+	} else if secret {
+		eg = "<secret>"
+	} else if fi.hasDefault {
 		eg = fi.defaultValue
-	} else if fi.customSetter == nil {
+	} else if fi.isJson {
+		// example is the JSON of the zero value...
+		zv := reflect.New(fv.Type()).Elem()
+		if fi.pointer {
+			zv = reflect.New(fv.Type().Elem())
+		}
+		var err error
+		if eg, err = jsonValueString(zv); err != nil {
+			return err
+		}
+	} else if enum := fi.exampleEnum(fv.Type()); enum != nil {
+		eg = strings.Join(enum.Names(), "|")
+	} else if st := stdTypeOf(fv.Type()); st != nil && fi.customSetter == nil {
+		eg = st.example
+	} else if fi.customSetter == nil && !isTextType(fv.Type()) {
 		switch fv.Type().Kind() {
 		case reflect.String:
 			eg = "<string>"
 		case reflect.Bool:
 			eg = "true|false"
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		case reflect.Int64:
+			if fv.Type() == durationType {
+				eg = "0s"
+			} else {
+				eg = "0"
+			}
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 			eg = "0"
+		case reflect.Complex64, reflect.Complex128:
+			eg = "(0+0i)"
+		case reflect.Array:
+			if fv.Type().Elem().Kind() == reflect.Uint8 {
+				eg = fmt.Sprintf("<%d bytes>", fv.Len())
+			} else {
+				eg = strings.TrimSuffix(strings.Repeat("value"+fi.delimiterAt(0), fv.Len()), fi.delimiterAt(0))
+			}
 		case reflect.Float32, reflect.Float64:
-			eg = "0.0"
-		case reflect.Slice:
-			eg = fmt.Sprintf("value%svalue%s...", fi.delimiter, fi.delimiter)
-		case reflect.Map:
-			eg = fmt.Sprintf("key%svalue%skey%svalue%s...", fi.separator, fi.delimiter, fi.separator, fi.delimiter)
+			if fi.unit == unitPercent {
+				eg = "0%"
+			} else {
+				eg = "0.0"
+			}
+		case reflect.Slice, reflect.Map:
+			if fi.format != CollectionFormatDelimited {
+				eg = exampleFormatted(fv.Type(), fi)
+			} else if fv.Kind() == reflect.Slice {
+				eg = fmt.Sprintf("value%svalue%s...", fi.delimiterAt(0), fi.delimiterAt(0))
+			} else if isSetItemType(fv.Type().Elem()) {
+				eg = fmt.Sprintf("key%skey%s...", fi.delimiterAt(0), fi.delimiterAt(0))
+			} else {
+				eg = fmt.Sprintf("key%svalue%skey%svalue%s...", fi.separator, fi.delimiterAt(0), fi.separator, fi.delimiterAt(0))
+			}
 		}
 	}
-	_, err := w.Write([]byte(name + "=" + eg + "\n"))
-	return err
+	*out = append(*out, EnvVar{Name: name, Value: eg, Secret: secret, Example: true})
+	return nil
 }
 
-func writeActualValue(w io.Writer, name string, fv reflect.Value, fi *fieldInfo) error {
+func writeActualValue(out *[]EnvVar, name string, fv reflect.Value, fi *fieldInfo, options *opts) error {
 	eg := "<value>"
-	skip := false
-	if fi.customSetter == nil {
+	secret := options.isSecret(name, fi)
+	if fi.pointer && fi.customSetter == nil && fv.IsNil() {
+		return nil
+	} else if fi.file {
+		// the value was loaded from a file - whose path is not known...
+		eg = "<file path>"
+	} else if fi.isJson {
+		var err error
+		if eg, err = jsonValueString(fv); err != nil {
+			return err
+		} else if secret {
+			eg = options.redact(eg)
+		}
+	} else if fi.customSetter == nil {
 		if fi.pointer {
-			skip = fv.IsNil()
 			fv = fv.Elem()
 		}
-		if !skip {
-			switch fv.Type().Kind() {
-			case reflect.String:
-				eg = fv.String()
-			case reflect.Bool:
-				if fv.Bool() {
-					eg = "true"
-				} else {
-					eg = "false"
-				}
-			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-				eg = fmt.Sprintf("%d", fv.Int())
-			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-				eg = fmt.Sprintf("%d", fv.Uint())
-			case reflect.Float32:
-				eg = strconv.FormatFloat(fv.Float(), 'f', -1, 32)
-			case reflect.Float64:
-				eg = strconv.FormatFloat(fv.Float(), 'f', -1, 64)
-			case reflect.Slice:
-				items := make([]string, 0)
-				for i := 0; i < fv.Len(); i++ {
-					items = append(items, fmt.Sprintf("%v", fv.Index(i).Interface()))
-				}
-				eg = strings.Join(items, fi.delimiter)
-			case reflect.Map:
-				items := make([]string, 0)
-				for _, mk := range fv.MapKeys() {
-					mv := fv.MapIndex(mk)
-					items = append(items, fmt.Sprintf("%v%s%v", mk.Interface(), fi.separator, mv.Interface()))
-				}
-				eg = strings.Join(items, fi.delimiter)
+		var err error
+		if eg, err = actualValueString(fv, fi, 0); err != nil {
+			return err
+		} else if eg = fi.encodeByteArray(fv, eg); secret {
+			eg = options.redact(eg)
+		}
+	}
+	*out = append(*out, EnvVar{Name: name, Value: eg, Secret: secret})
+	return nil
+}
+
+// actualValueString returns the string of a value - where level is the collection nesting level (determining the delimiter
+// used for slices and maps)
+func actualValueString(fv reflect.Value, fi *fieldInfo, level int) (string, error) {
+	if s, ok := fi.enumValueString(fv); ok {
+		return s, nil
+	} else if s, ok := textValue(fv); ok {
+		return s, nil
+	} else if level == 0 && fi.format != CollectionFormatDelimited && isCollectionType(fv.Type()) {
+		return formattedValueString(fv, fi)
+	} else if s, ok := fi.unit.format(fv); ok {
+		return s, nil
+	}
+	switch fv.Type().Kind() {
+	case reflect.String:
+		return fv.String(), nil
+	case reflect.Bool:
+		if fv.Bool() {
+			return "true", nil
+		}
+		return "false", nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if fv.Type() == durationType {
+			return time.Duration(fv.Int()).String(), nil
+		}
+		return fmt.Sprintf("%d", fv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return fmt.Sprintf("%d", fv.Uint()), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(fv.Float(), 'f', -1, 32), nil
+	case reflect.Float64:
+		return strconv.FormatFloat(fv.Float(), 'f', -1, 64), nil
+	case reflect.Complex64, reflect.Complex128:
+		return strconv.FormatComplex(fv.Complex(), 'g', -1, fv.Type().Bits()), nil
+	case reflect.Array:
+		if fv.Type().Elem().Kind() == reflect.Uint8 {
+			data := make([]byte, fv.Len())
+			reflect.Copy(reflect.ValueOf(data), fv)
+			return string(data), nil
+		}
+		items := make([]string, 0, fv.Len())
+		for i := 0; i < fv.Len(); i++ {
+			item, err := collectionItemString(fv.Index(i), fi, level)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, item)
+		}
+		return strings.Join(items, fi.delimiterAt(level)), nil
+	case reflect.Slice:
+		if fv.Type().Elem().Kind() == reflect.Uint8 {
+			return string(fv.Bytes()), nil
+		}
+		items := make([]string, 0)
+		for i := 0; i < fv.Len(); i++ {
+			item, err := collectionItemString(fv.Index(i), fi, level)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, item)
+		}
+		return strings.Join(items, fi.delimiterAt(level)), nil
+	case reflect.Map:
+		items := make([]string, 0)
+		isSet := isSetItemType(fv.Type().Elem())
+		for _, mk := range fv.MapKeys() {
+			if isSet {
+				items = append(items, fi.quoteItem(fi.itemString(mk)))
+			} else if item, err := collectionItemString(fv.MapIndex(mk), fi, level); err != nil {
+				return "", err
+			} else {
+				items = append(items, fi.quoteItem(fi.itemString(mk))+fi.separator+item)
 			}
 		}
+		return strings.Join(items, fi.delimiterAt(level)), nil
 	}
-	var err error
-	if !skip {
-		_, err = w.Write([]byte(name + "=" + eg + "\n"))
+	return "<value>", nil
+}
+
+func collectionItemString(v reflect.Value, fi *fieldInfo, level int) (string, error) {
+	if isCollectionType(v.Type()) {
+		return actualValueString(v, fi, level+1)
+	}
+	return fi.quoteItem(fi.itemString(v)), nil
+}
+
+// itemString returns the string of a collection item - using the field's unit (if any)
+func (fi *fieldInfo) itemString(v reflect.Value) string {
+	if s, ok := fi.enumValueString(v); ok {
+		return s
+	} else if s, ok := fi.unit.format(v); ok {
+		return s
+	}
+	return itemString(v)
+}
+
+func itemString(v reflect.Value) string {
+	if s, ok := textValue(v); ok {
+		return s
+	} else if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
 	}
-	return err
+	return fmt.Sprintf("%v", v.Interface())
 }
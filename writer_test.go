@@ -7,6 +7,7 @@ import (
 	"github.com/go-andiamo/gopt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"strings"
 	"testing"
 )
 
@@ -225,7 +226,7 @@ func TestExample(t *testing.T) {
 				Mapped map[string]string `env:"prefix=APP_"`
 			}{
 				Mapped: map[string]string{
-					"APP_FOO": "foo",
+					"FOO": "foo",
 				},
 			},
 			actual: true,
@@ -533,7 +534,7 @@ func TestExample_ErrorsWithWriteError(t *testing.T) {
 				Mapped map[string]string `env:"prefix=APP_"`
 			}{
 				Mapped: map[string]string{
-					"APP_FOO": "foo",
+					"FOO": "foo",
 				},
 			},
 			actual: true,
@@ -636,3 +637,127 @@ func TestWrite_Optional_WithExample(t *testing.T) {
 `
 	require.Equal(t, expect, w.String())
 }
+
+func TestWrite_ComplexAndArrays(t *testing.T) {
+	type config struct {
+		C64   complex64
+		C128  complex128
+		Point [3]float64
+		Names [2]string `env:"delim=;"`
+		Raw   [3]byte
+		Sizes [2]int `env:"unit=bytes"`
+	}
+	cfg := &config{
+		C64:   1 + 2i,
+		C128:  -1.5i,
+		Point: [3]float64{1.5, 2, -3},
+		Names: [2]string{"a", "b"},
+		Raw:   [3]byte{'a', 'b', 'c'},
+		Sizes: [2]int{1024, 2048},
+	}
+	var w bytes.Buffer
+	err := Write(&w, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, `C64=(1+2i)
+C128=(0-1.5i)
+POINT=1.5,2,-3
+NAMES=a;b
+RAW=abc
+SIZES=1KiB,2KiB
+`, w.String())
+
+	w.Reset()
+	err = Example(&w, &config{})
+	require.NoError(t, err)
+	assert.Equal(t, `C64=(0+0i)
+C128=(0+0i)
+POINT=value,value,value
+NAMES=value;value
+RAW=<3 bytes>
+SIZES=value,value
+`, w.String())
+}
+
+func TestWrite_Encoded(t *testing.T) {
+	type config struct {
+		Key   [32]byte `env:"encoding=base64"`
+		Raw   [4]byte  `env:"encoding=rawBase64url"`
+		Token string   `env:"encoding=base64url"`
+		Data  []byte   `env:"encoding=rawBase64"`
+		Plain [4]byte
+	}
+	cfg := &config{
+		Raw:   [4]byte{0xfb, 0xff, 0, 1},
+		Token: "a?b>c",
+		Data:  []byte("abc"),
+		Plain: [4]byte{'a', 'b', 'c', 'd'},
+	}
+	for i := range cfg.Key {
+		cfg.Key[i] = byte(i * 8)
+	}
+	var w bytes.Buffer
+	err := Write(&w, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, `KEY=AAgQGCAoMDhASFBYYGhweICIkJigqLC4wMjQ2ODo8Pg=
+RAW=-_8AAQ
+TOKEN=a?b>c
+DATA=abc
+PLAIN=abcd
+`, w.String())
+
+	type arrays struct {
+		Key   [32]byte `env:"encoding=base64"`
+		Raw   [4]byte  `env:"encoding=rawBase64url"`
+		Plain [4]byte
+	}
+	written := &arrays{Key: cfg.Key, Raw: cfg.Raw, Plain: cfg.Plain}
+	w.Reset()
+	err = Write(&w, written)
+	require.NoError(t, err)
+	loaded := &arrays{}
+	err = Load(loaded, NewEnvFileReader(&w, nil))
+	require.NoError(t, err)
+	assert.Equal(t, written, loaded)
+}
+
+func TestWrite_Encoded_CustomDecoder(t *testing.T) {
+	type config struct {
+		Value [3]byte `env:"encoding=upper"`
+	}
+	var w bytes.Buffer
+	err := Write(&w, &config{Value: [3]byte{'a', 'b', 'c'}}, &testUpperDecoder{})
+	require.NoError(t, err)
+	assert.Equal(t, "VALUE=abc\n", w.String())
+}
+
+type testUpperDecoder struct{}
+
+func (d *testUpperDecoder) Encoding() string {
+	return "upper"
+}
+
+func (d *testUpperDecoder) Decode(value string) (string, error) {
+	return strings.ToUpper(value), nil
+}
+
+func TestWrite_IgnoredFields(t *testing.T) {
+	type embedded struct {
+		Inner string
+	}
+	type config struct {
+		embedded `env:"-"`
+		Name     string
+		Cache    map[string]any `env:"-"`
+		Derived  string         `env:"-"`
+	}
+	cfg := &config{Name: "foo", Derived: "bar", embedded: embedded{Inner: "baz"}}
+	var w bytes.Buffer
+	err := Write(&w, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "NAME=foo\n", w.String())
+
+	w.Reset()
+	err = Example(&w, &config{})
+	require.NoError(t, err)
+	assert.Equal(t, "NAME=<string>\n", w.String())
+}